@@ -1,12 +1,17 @@
 package bootstrap
 
 import (
+	"log"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/sayyidinside/gofiber-clean-fresh/cmd/worker"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/breach"
 	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
 	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/database"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/mail"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/oidc"
 	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/rabbitmq"
 	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
@@ -17,19 +22,72 @@ import (
 )
 
 func Initialize(app *fiber.App, db *gorm.DB, cacheRedis *redis.CacheClient, lockRedis *redis.LockClient) {
+	// Infrastructure client
+	mailClient := mail.NewMailClient(config.AppConfig)
+	oidcClient := oidc.NewClient()
+	breachLookup, err := breach.NewLookup(config.AppConfig)
+	if err != nil {
+		log.Fatalf("failed loading breached password list: %v", err)
+	}
+
 	// Repositories
 	userRepo := repository.NewUserRepository(db)
 	permissionRepo := repository.NewPermissionRepository(db)
 	moduleRepo := repository.NewModuleRepository(db)
 	roleRepo := repository.NewRoleRepository(db)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	apiKeyRepo := repository.NewApiKeyRepository(db)
+	clientRepo := repository.NewClientRepository(db)
+	passwordHistoryRepo := repository.NewPasswordHistoryRepository(db)
+	userIdentityRepo := repository.NewUserIdentityRepository(db)
+	userGrantRepo := repository.NewUserGrantRepository(db)
+	userPermissionRepo := repository.NewUserPermissionRepository(db)
 
 	// Service
-	userService := service.NewUserService(userRepo, roleRepo, cacheRedis)
-	permissionService := service.NewPermissionService(permissionRepo, moduleRepo)
-	moduleService := service.NewModuleService(moduleRepo)
-	roleService := service.NewRoleService(roleRepo, permissionRepo)
-	authService := service.NewAuthService(refreshTokenRepo, userRepo)
+	tokenDenyListService := service.NewTokenDenyListService(refreshTokenRepo, cacheRedis)
+	tokenVersionService := service.NewTokenVersionService(userRepo, roleRepo, clientRepo, cacheRedis)
+	rolePermissionService := service.NewRolePermissionService(roleRepo, cacheRedis)
+	permissionImplicationService := service.NewPermissionImplicationService(permissionRepo, cacheRedis)
+	loginThrottleService := service.NewLoginThrottleService(cacheRedis)
+	passwordPolicyService := service.NewPasswordPolicyService(passwordHistoryRepo, breachLookup)
+	userService := service.NewUserService(
+		userRepo, roleRepo, refreshTokenRepo, cacheRedis, mailClient, tokenDenyListService, tokenVersionService, loginThrottleService, passwordPolicyService,
+	)
+	permissionService := service.NewPermissionService(
+		permissionRepo, moduleRepo, tokenDenyListService, tokenVersionService, rolePermissionService, permissionImplicationService,
+	)
+	moduleService := service.NewModuleService(moduleRepo, permissionImplicationService)
+	roleService := service.NewRoleService(roleRepo, permissionRepo, tokenDenyListService, tokenVersionService, rolePermissionService)
+	authService := service.NewAuthService(
+		refreshTokenRepo, userRepo, roleRepo, twoFactorRepo, passwordResetRepo, mailClient, tokenDenyListService, loginThrottleService, passwordPolicyService,
+	)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo, userRepo, refreshTokenRepo, loginThrottleService)
+	sessionService := service.NewSessionService(refreshTokenRepo, userRepo, tokenDenyListService)
+	apiKeyService := service.NewApiKeyService(apiKeyRepo, userRepo, permissionRepo, permissionImplicationService)
+	clientService := service.NewClientService(clientRepo, permissionRepo, tokenVersionService)
+	impersonationService := service.NewImpersonationService(userRepo, tokenDenyListService)
+	oidcService := service.NewOidcService(
+		oidcClient, userRepo, userIdentityRepo, roleRepo, refreshTokenRepo, twoFactorRepo, cacheRedis, tokenDenyListService, tokenVersionService,
+		loginThrottleService,
+	)
+	userGrantService := service.NewUserGrantService(
+		userGrantRepo, userRepo, roleRepo, permissionRepo, refreshTokenRepo, tokenDenyListService, tokenVersionService,
+	)
+	userPermissionService := service.NewUserPermissionService(
+		userPermissionRepo, userRepo, permissionRepo, tokenDenyListService, tokenVersionService,
+	)
+
+	// Middleware dependency
+	middleware.InitTokenDenyList(tokenDenyListService)
+	middleware.InitTokenVersion(tokenVersionService)
+	middleware.InitPermissionResolver(rolePermissionService)
+	middleware.InitPermissionExpander(permissionImplicationService)
+	middleware.InitApiKeyAuthenticator(apiKeyService)
+
+	// Background job
+	worker.StartGrantWorker(userGrantService, lockRedis)
 
 	// Handler
 	userHandler := handler.NewUserHandler(userService)
@@ -37,16 +95,41 @@ func Initialize(app *fiber.App, db *gorm.DB, cacheRedis *redis.CacheClient, lock
 	moduleHandler := handler.NewModuleHandler(moduleService)
 	roleHandler := handler.NewRoleHandler(roleService)
 	authHandler := handler.NewAuthHandler(authService)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	apiKeyHandler := handler.NewApiKeyHandler(apiKeyService)
+	clientHandler := handler.NewClientHandler(clientService)
+	oauthHandler := handler.NewOAuthHandler(clientService)
+	oidcHandler := handler.NewOidcHandler(oidcService)
+	impersonationHandler := handler.NewImpersonationHandler(impersonationService)
+	meHandler := handler.NewMeHandler(userService)
+	userGrantHandler := handler.NewUserGrantHandler(userGrantService)
+	userPermissionHandler := handler.NewUserPermissionHandler(userPermissionService)
+	wellKnownHandler := handler.NewWellKnownHandler()
 
 	// Setup handler to send to routes setup
 	handler := &handler.Handlers{
 		UserManagementHandler: &handler.UserManagementHandler{
-			UserHandler:       userHandler,
-			PermissionHandler: permissionHandler,
-			ModuleHandler:     moduleHandler,
-			RoleHandler:       roleHandler,
+			UserHandler:           userHandler,
+			PermissionHandler:     permissionHandler,
+			ModuleHandler:         moduleHandler,
+			RoleHandler:           roleHandler,
+			SessionHandler:        sessionHandler,
+			ClientHandler:         clientHandler,
+			UserGrantHandler:      userGrantHandler,
+			UserPermissionHandler: userPermissionHandler,
+		},
+		AuthManagementHandler: &handler.AuthManagementHandler{
+			AuthHandler:          authHandler,
+			TwoFactorHandler:     twoFactorHandler,
+			SessionHandler:       sessionHandler,
+			ApiKeyHandler:        apiKeyHandler,
+			OidcHandler:          oidcHandler,
+			ImpersonationHandler: impersonationHandler,
 		},
-		AuthHandler: authHandler,
+		OAuthHandler:     oauthHandler,
+		MeHandler:        meHandler,
+		WellKnownHandler: wellKnownHandler,
 	}
 
 	routes.Setup(app, handler)
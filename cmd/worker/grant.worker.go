@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+)
+
+// StartGrantWorker periodically apply time-bound grant that start or end, lock make sure only
+// one instance process them when the app is scaled out
+func StartGrantWorker(grantService service.UserGrantService, lockRedis *redis.LockClient) {
+	interval := time.Duration(config.AppConfig.GrantJobInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx := context.WithValue(context.Background(), constant.CtxKeyUsername, "grant-worker")
+
+			// Lock is kept until it expire instead of released, so other instance ticking
+			// around the same time skip this run instead of repeating it
+			acquired, err := lockRedis.AcquireLock(ctx, constant.LockKeyGrantJob, interval/2)
+			if err != nil || !acquired {
+				continue
+			}
+
+			// Failure is logged by the service, due grant is picked up again on next run
+			grantService.ProcessDue(ctx)
+		}
+	}()
+}
@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+	"gorm.io/gorm"
+)
+
+type ApiKey struct {
+	ID     uint      `json:"id" gorm:"primaryKey"`
+	UUID   uuid.UUID `json:"uuid" gorm:"uniqueIndex;type:char(36)"`
+	UserID uint      `json:"user_id" gorm:"index;not null"`
+	Name   string    `json:"name" gorm:"size:100;not null"`
+
+	// Prefix is the first part of the key, kept in plaintext so user could recognize the key
+	Prefix  string `json:"prefix" gorm:"size:16"`
+	KeyHash string `json:"-" gorm:"size:64;uniqueIndex;not null"`
+
+	ExpiredAt  time.Time    `json:"expired_at" gorm:"index"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	LastUsedIP string       `json:"last_used_ip" gorm:"size:45"`
+
+	// Relationship
+	User        User         `json:"user" gorm:"foreignKey:UserID"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:api_key_permissions;"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+func (ApiKey) TableName() string {
+	return constant.TABLE_API_KEY
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field.
+func (a *ApiKey) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.UUID == uuid.Nil {
+		a.UUID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+	"gorm.io/gorm"
+)
+
+// Client is non human caller (internal service) authenticated with OAuth2 client credentials
+type Client struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UUID       uuid.UUID `json:"uuid" gorm:"uniqueIndex;type:char(36)"`
+	ClientID   string    `json:"client_id" gorm:"size:64;uniqueIndex;not null"`
+	Name       string    `json:"name" gorm:"size:100;not null"`
+	SecretHash string    `json:"-" gorm:"size:64;not null"`
+
+	// TokenVersion embedded in issued token, bumped on secret rotation, scope change and deletion
+	TokenVersion uint `json:"-" gorm:"not null;default:0"`
+
+	// Relationship
+	Scopes []Permission `json:"scopes" gorm:"many2many:client_permissions;"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+func (Client) TableName() string {
+	return constant.TABLE_CLIENT
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field.
+func (c *Client) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.UUID == uuid.Nil {
+		c.UUID = uuid.New()
+	}
+	return
+}
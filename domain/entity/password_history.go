@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+)
+
+// PasswordHistory keep hash of password previously set by user, so it could not be reused
+type PasswordHistory struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"index;not null"`
+	PasswordHash string    `json:"-" gorm:"size:60;not null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+}
+
+func (PasswordHistory) TableName() string {
+	return constant.TABLE_PASSWORD_HISTORY
+}
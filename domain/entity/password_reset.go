@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+)
+
+type PasswordReset struct {
+	ID        uint         `json:"id" gorm:"primaryKey"`
+	UserID    uint         `json:"user_id" gorm:"index;not null"`
+	TokenHash string       `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	ExpiredAt time.Time    `json:"expired_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+
+	// Relationship
+	User User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+func (PasswordReset) TableName() string {
+	return constant.TABLE_PASSWORD_RESET
+}
@@ -18,6 +18,10 @@ type Permission struct {
 	Module Module `json:"module" gorm:"foreignKey:ModuleID"`
 	Roles  []Role `json:"roles" gorm:"many2many:role_permissions;"`
 
+	// Implies are granted along with this permission (e.g. "Update User" implies "View User"),
+	// permission named "<Module>:*" additionally implies every permission of the module
+	Implies []Permission `json:"implies" gorm:"many2many:permission_implications;joinForeignKey:PermissionID;joinReferences:ImpliedID"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
@@ -0,0 +1,16 @@
+package entity
+
+import "github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+
+type PermissionImplication struct {
+	PermissionID uint `gorm:"primaryKey"`
+	ImpliedID    uint `gorm:"primaryKey"`
+
+	// Relationships
+	Permission Permission `gorm:"foreignKey:PermissionID"`
+	Implied    Permission `gorm:"foreignKey:ImpliedID"`
+}
+
+func (PermissionImplication) TableName() string {
+	return constant.TABLE_PERMISSION_IMPLICATION
+}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,9 +11,28 @@ import (
 )
 
 type RefreshToken struct {
-	UUID      uuid.UUID `json:"uuid" gorm:"primaryKey"`
-	UserID    uint      `json:"user_id"`
-	Token     string    `json:"token" gorm:"index,type:longtext"`
+	UUID   uuid.UUID `json:"uuid" gorm:"primaryKey"`
+	UserID uint      `json:"user_id"`
+
+	// Only SHA-256 digest of the signed token is stored, so leaked row can not be used as session
+	TokenHash string `json:"-" gorm:"size:64;uniqueIndex"`
+
+	// Latest access token issued together with this refresh token, used to deny it on revocation
+	AccessJTI       string    `json:"-" gorm:"size:36"`
+	AccessExpiredAt time.Time `json:"-" gorm:"index"`
+
+	// Token family, every rotation create child in the same family so replay of
+	// rotated token could revoke the whole device chain
+	FamilyID  uuid.UUID    `json:"family_id" gorm:"type:char(36);index"`
+	ParentID  *uuid.UUID   `json:"parent_id" gorm:"type:char(36)"`
+	RotatedAt sql.NullTime `json:"rotated_at" gorm:"index"`
+
+	// Client that the token issued to, recorded at login and carried forward on rotation
+	IPAddress  string       `json:"ip_address" gorm:"size:45"`
+	UserAgent  string       `json:"user_agent" gorm:"size:255"`
+	Device     string       `json:"device" gorm:"size:100"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+
 	User      User      `json:"user" gorm:"foreignKey:UserID"`
 	CreatedAt time.Time `json:"created_at"`
 	ExpiredAt time.Time `json:"expired_at"`
@@ -22,6 +42,16 @@ func (RefreshToken) TableName() string {
 	return constant.TABLE_REFRESH_TOKEN
 }
 
+// SessionID return id of the login session token belong to, token issued before
+// family introduced is its own session
+func (r *RefreshToken) SessionID() uuid.UUID {
+	if r.FamilyID == uuid.Nil {
+		return r.UUID
+	}
+
+	return r.FamilyID
+}
+
 // BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
 // It generates a new UUID for the UUID field.
 func (r *RefreshToken) BeforeCreate(tx *gorm.DB) (err error) {
@@ -31,6 +61,11 @@ func (r *RefreshToken) BeforeCreate(tx *gorm.DB) (err error) {
 		r.UUID = uuid.New()
 	}
 
+	// Token without parent start new family
+	if r.FamilyID == uuid.Nil {
+		r.FamilyID = r.UUID
+	}
+
 	r.ExpiredAt = time.Now().Add(time.Duration(cfg.JwtRefreshTime) * time.Hour)
 	return
 }
@@ -9,15 +9,26 @@ import (
 )
 
 type Role struct {
-	ID      uint      `json:"id" gorm:"primaryKey"`
-	UUID    uuid.UUID `json:"uuid" gorm:"uniqueIndex;type:char(36)"`
-	Name    string    `json:"name" gorm:"size:50;uniqueIndex;not null"`
-	IsAdmin bool      `json:"is_admin" gorm:"default:false"`
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	UUID             uuid.UUID `json:"uuid" gorm:"uniqueIndex;type:char(36)"`
+	Name             string    `json:"name" gorm:"size:50;uniqueIndex;not null"`
+	IsAdmin          bool      `json:"is_admin" gorm:"default:false"`
+	RequireTwoFactor bool      `json:"require_two_factor" gorm:"default:false"`
+
+	// Bumped whenever role permissions change, access token with older version is rejected
+	TokenVersion uint `json:"-" gorm:"not null;default:0"`
 
 	// Relationship
 	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions;"`
 	Users       []User       `json:"users" gorm:"foreignKey:RoleID"`
 
+	// Parents grant their permissions (and their own parents permissions) to this role,
+	// admin and two-factor flag are not inherited
+	Parents []Role `json:"parents" gorm:"many2many:role_parents;joinForeignKey:RoleID;joinReferences:ParentID"`
+
+	// InheritedPermissions is filled by repository from every ancestor, never persisted
+	InheritedPermissions []Permission `json:"inherited_permissions" gorm:"-"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
@@ -35,3 +46,8 @@ func (r *Role) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+// AllPermissions return direct permissions followed by inherited ones
+func (r *Role) AllPermissions() []Permission {
+	return append(append([]Permission{}, r.Permissions...), r.InheritedPermissions...)
+}
@@ -0,0 +1,16 @@
+package entity
+
+import "github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+
+type RoleParent struct {
+	RoleID   uint `gorm:"primaryKey"`
+	ParentID uint `gorm:"primaryKey"`
+
+	// Relationships
+	Role   Role `gorm:"foreignKey:RoleID"`
+	Parent Role `gorm:"foreignKey:ParentID"`
+}
+
+func (RoleParent) TableName() string {
+	return constant.TABLE_ROLE_PARENT
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+)
+
+type TwoFactorRecoveryCode struct {
+	ID              uint         `json:"id" gorm:"primaryKey"`
+	UserTwoFactorID uint         `json:"user_two_factor_id" gorm:"index;not null"`
+	CodeHash        string       `json:"-" gorm:"not null"`
+	UsedAt          sql.NullTime `json:"used_at"`
+	CreatedAt       time.Time    `json:"created_at"`
+}
+
+func (TwoFactorRecoveryCode) TableName() string {
+	return constant.TABLE_TWO_FACTOR_RECOVERY_CODE
+}
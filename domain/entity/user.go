@@ -2,6 +2,8 @@ package entity
 
 import (
 	"database/sql"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
@@ -17,7 +19,28 @@ type User struct {
 	Email       string       `json:"email" gorm:"index"`
 	Password    string       `json:"password"`
 	ValidatedAt sql.NullTime `json:"validated_at" gorm:"index"`
-	Role        Role         `json:"role" gorm:"foreignKey:RoleID"`
+
+	// Null for password set before policy tracked it, creation time is used instead
+	PasswordChangedAt sql.NullTime `json:"password_changed_at"`
+
+	// Self registered user waiting for admin approval could not login
+	PendingApproval bool `json:"pending_approval" gorm:"not null;default:false"`
+
+	// Self registered user could not login until its email verified, user created by admin could
+	SelfRegistered bool `json:"self_registered" gorm:"not null;default:false"`
+
+	// Bumped whenever role change, access token with older version is rejected
+	TokenVersion uint `json:"-" gorm:"not null;default:0"`
+
+	// Role is the primary role, Roles hold every assigned role including the primary one
+	Role  Role   `json:"role" gorm:"foreignKey:RoleID"`
+	Roles []Role `json:"roles" gorm:"many2many:user_roles;"`
+
+	// Grants only hold grants in effect, loaded along with roles when generating token
+	Grants []UserGrant `json:"grants" gorm:"foreignKey:UserID"`
+
+	// Permission allowed or denied to user directly, deny win over anything else
+	PermissionOverrides []UserPermission `json:"permission_overrides" gorm:"foreignKey:UserID"`
 	gorm.Model
 }
 
@@ -44,3 +67,154 @@ func (u *User) GetID() uint {
 
 	return u.ID
 }
+
+// AllRoles return every role assigned to user, user loaded without Roles (or created before
+// multiple role supported) fall back to its primary role
+func (u *User) AllRoles() []Role {
+	if len(u.Roles) != 0 {
+		return u.Roles
+	}
+
+	if u.RoleID == 0 {
+		return nil
+	}
+
+	return []Role{u.Role}
+}
+
+// EffectiveRoles return every assigned role followed by role granted for limited time,
+// grant not in effect anymore is skipped in case it is not expired by grant job yet
+func (u *User) EffectiveRoles() []Role {
+	roles := append([]Role{}, u.AllRoles()...)
+
+	now := time.Now()
+	for _, grant := range u.Grants {
+		if grant.RoleID == nil || grant.Role.ID == 0 || !grant.IsActive(now) {
+			continue
+		}
+
+		if slices.ContainsFunc(roles, func(role Role) bool { return role.ID == grant.Role.ID }) {
+			continue
+		}
+
+		roles = append(roles, grant.Role)
+	}
+
+	return roles
+}
+
+// GrantedPermissionNames return name of permission granted directly to user for limited time
+func (u *User) GrantedPermissionNames() []string {
+	names := []string{}
+
+	now := time.Now()
+	for _, grant := range u.Grants {
+		if grant.PermissionID == nil || grant.Permission.ID == 0 || !grant.IsActive(now) {
+			continue
+		}
+
+		if !slices.Contains(names, grant.Permission.Name) {
+			names = append(names, grant.Permission.Name)
+		}
+	}
+
+	return names
+}
+
+// DirectPermissionNames return name of permission given to user outside its roles, either
+// granted for limited time or allowed by override
+func (u *User) DirectPermissionNames() []string {
+	names := u.GrantedPermissionNames()
+
+	for _, override := range u.PermissionOverrides {
+		if override.IsDeny() || override.Permission.ID == 0 {
+			continue
+		}
+
+		if !slices.Contains(names, override.Permission.Name) {
+			names = append(names, override.Permission.Name)
+		}
+	}
+
+	return names
+}
+
+// DeniedPermissionNames return name of permission denied to user by override
+func (u *User) DeniedPermissionNames() []string {
+	names := []string{}
+
+	for _, override := range u.PermissionOverrides {
+		if override.IsDeny() && override.Permission.ID != 0 {
+			names = append(names, override.Permission.Name)
+		}
+	}
+
+	return names
+}
+
+// RoleIDs return id of every role assigned to user
+func (u *User) RoleIDs() []uint {
+	roles := u.AllRoles()
+
+	ids := make([]uint, 0, len(roles))
+	for _, role := range roles {
+		ids = append(ids, role.ID)
+	}
+
+	return ids
+}
+
+// IsAdmin report whether any role assigned or granted to user is admin
+func (u *User) IsAdmin() bool {
+	for _, role := range u.EffectiveRoles() {
+		if role.IsAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequireTwoFactor report whether any role assigned or granted to user enforce two-factor
+func (u *User) RequireTwoFactor() bool {
+	for _, role := range u.EffectiveRoles() {
+		if role.RequireTwoFactor {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PermissionNames return union of permission names granted by every role of user, including
+// inherited ones and permission given directly, without permission denied by override, roles
+// must be loaded along with their permissions
+func (u *User) PermissionNames() []string {
+	seen := map[string]struct{}{}
+	names := []string{}
+
+	// Denied name is marked seen up front so it is never added
+	for _, name := range u.DeniedPermissionNames() {
+		seen[name] = struct{}{}
+	}
+
+	for _, role := range u.EffectiveRoles() {
+		for _, permission := range role.AllPermissions() {
+			if _, exists := seen[permission.Name]; exists {
+				continue
+			}
+
+			seen[permission.Name] = struct{}{}
+			names = append(names, permission.Name)
+		}
+	}
+
+	for _, name := range u.DirectPermissionNames() {
+		if _, exists := seen[name]; !exists {
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
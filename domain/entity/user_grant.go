@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+	"gorm.io/gorm"
+)
+
+// UserGrant give user a role or a single permission for limited time, exactly one of RoleID
+// and PermissionID is set, null ValidUntil mean the grant never expire
+type UserGrant struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`
+	UUID         uuid.UUID    `json:"uuid" gorm:"uniqueIndex;type:char(36)"`
+	UserID       uint         `json:"user_id" gorm:"index;not null"`
+	RoleID       *uint        `json:"role_id" gorm:"index"`
+	PermissionID *uint        `json:"permission_id" gorm:"index"`
+	ValidFrom    time.Time    `json:"valid_from" gorm:"index;not null"`
+	ValidUntil   sql.NullTime `json:"valid_until" gorm:"index"`
+	Reason       string       `json:"reason" gorm:"size:255"`
+	GrantedBy    uint         `json:"granted_by"`
+
+	// Set by grant job once the grant start or end, so each transition is handled only once
+	ActivatedAt sql.NullTime `json:"activated_at" gorm:"index"`
+	ExpiredAt   sql.NullTime `json:"expired_at" gorm:"index"`
+
+	// Relationship
+	User       User       `json:"user" gorm:"foreignKey:UserID"`
+	Role       Role       `json:"role" gorm:"foreignKey:RoleID"`
+	Permission Permission `json:"permission" gorm:"foreignKey:PermissionID"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+func (UserGrant) TableName() string {
+	return constant.TABLE_USER_GRANT
+}
+
+// BeforeCreate is a GORM hook that is triggered before a new record is inserted into the database.
+// It generates a new UUID for the UUID field.
+func (g *UserGrant) BeforeCreate(tx *gorm.DB) (err error) {
+	if g.UUID == uuid.Nil {
+		g.UUID = uuid.New()
+	}
+	return
+}
+
+// IsActive report whether grant is in effect at given time
+func (g *UserGrant) IsActive(at time.Time) bool {
+	if g.ValidFrom.After(at) {
+		return false
+	}
+
+	return !g.ValidUntil.Valid || g.ValidUntil.Time.After(at)
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+)
+
+// UserIdentity link user to subject of external OpenID Connect provider, so later login is
+// matched by subject instead of email. User has at most one identity per provider
+type UserIdentity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_identity_user_provider"`
+	Provider  string    `json:"provider" gorm:"size:100;not null;uniqueIndex:idx_user_identity_subject;uniqueIndex:idx_user_identity_user_provider"`
+	Subject   string    `json:"subject" gorm:"size:255;not null;uniqueIndex:idx_user_identity_subject"`
+	Email     string    `json:"email" gorm:"size:255"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (UserIdentity) TableName() string {
+	return constant.TABLE_USER_IDENTITY
+}
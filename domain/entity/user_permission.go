@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+)
+
+// UserPermission allow or deny single permission for user on top of its roles, user has at
+// most one override per permission
+type UserPermission struct {
+	UserID       uint   `json:"user_id" gorm:"primaryKey"`
+	PermissionID uint   `json:"permission_id" gorm:"primaryKey"`
+	Effect       string `json:"effect" gorm:"size:5;not null"`
+	Reason       string `json:"reason" gorm:"size:255"`
+	GrantedBy    uint   `json:"granted_by"`
+
+	// Relationships
+	User       User       `json:"user" gorm:"foreignKey:UserID"`
+	Permission Permission `json:"permission" gorm:"foreignKey:PermissionID"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (UserPermission) TableName() string {
+	return constant.TABLE_USER_PERMISSION
+}
+
+func (p *UserPermission) IsDeny() bool {
+	return p.Effect == constant.PermissionEffectDeny
+}
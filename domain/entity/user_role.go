@@ -0,0 +1,16 @@
+package entity
+
+import "github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+
+type UserRole struct {
+	UserID uint `gorm:"primaryKey"`
+	RoleID uint `gorm:"primaryKey"`
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID"`
+	Role Role `gorm:"foreignKey:RoleID"`
+}
+
+func (UserRole) TableName() string {
+	return constant.TABLE_USER_ROLE
+}
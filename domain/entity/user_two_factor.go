@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+)
+
+type UserTwoFactor struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`
+	UserID       uint         `json:"user_id" gorm:"uniqueIndex;not null"`
+	Secret       string       `json:"-" gorm:"size:64;not null"`
+	LastUsedStep int64        `json:"-" gorm:"default:0"`
+	EnabledAt    sql.NullTime `json:"enabled_at"`
+
+	// Relationship
+	User          User                    `json:"user" gorm:"foreignKey:UserID"`
+	RecoveryCodes []TwoFactorRecoveryCode `json:"recovery_codes" gorm:"foreignKey:UserTwoFactorID"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (UserTwoFactor) TableName() string {
+	return constant.TABLE_USER_TWO_FACTOR
+}
+
+func (t *UserTwoFactor) IsEnabled() bool {
+	if t == nil {
+		return false
+	}
+
+	return t.EnabledAt.Valid
+}
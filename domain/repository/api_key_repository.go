@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type ApiKeyRepository interface {
+	FindAllByUserID(ctx context.Context, userID uint) (*[]entity.ApiKey, error)
+	FindValidByKeyHash(ctx context.Context, keyHash string) (*entity.ApiKey, error)
+	Insert(ctx context.Context, apiKey *entity.ApiKey) error
+	DeleteByUUID(ctx context.Context, userID uint, uuid uuid.UUID) error
+	TouchLastUsed(ctx context.Context, apiKey *entity.ApiKey, ip string) error
+}
+
+type apiKeyRepository struct {
+	*gorm.DB
+}
+
+func NewApiKeyRepository(db *gorm.DB) ApiKeyRepository {
+	return &apiKeyRepository{DB: db}
+}
+
+func (r *apiKeyRepository) FindAllByUserID(ctx context.Context, userID uint) (*[]entity.ApiKey, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var apiKeys []entity.ApiKey
+
+	if err := r.DB.WithContext(ctx).Where("user_id = ?", userID).
+		Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name")
+		}).
+		Order("created_at DESC").
+		Find(&apiKeys).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &apiKeys, nil
+}
+
+// FindValidByKeyHash only return key that not expired, along with owner and owner roles permissions
+// so key scope could be checked against what the owner currently allowed
+func (r *apiKeyRepository) FindValidByKeyHash(ctx context.Context, keyHash string) (*entity.ApiKey, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var apiKey entity.ApiKey
+	result := r.DB.WithContext(ctx).Limit(1).
+		Where("key_hash = ? AND expired_at > ?", keyHash, time.Now()).
+		Preload("Permissions").
+		Preload("User").
+		Preload("User.Role").
+		Preload("User.Role.Permissions").
+		Preload("User.Roles").
+		Preload("User.Roles.Permissions").
+		Preload("User.PermissionOverrides.Permission").
+		Find(&apiKey)
+
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("api key not found")
+	}
+
+	if err := loadUserInheritedPermissions(ctx, r.DB, &apiKey.User); err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	if err := loadUserActiveGrants(ctx, r.DB, &apiKey.User); err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+func (r *apiKeyRepository) Insert(ctx context.Context, apiKey *entity.ApiKey) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Create(apiKey).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// DeleteByUUID revoke key owned by user
+func (r *apiKeyRepository) DeleteByUUID(ctx context.Context, userID uint, uuid uuid.UUID) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	result := r.DB.WithContext(ctx).Where("user_id = ? AND uuid = ?", userID, uuid).Delete(&entity.ApiKey{})
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("api key not found")
+	}
+
+	return nil
+}
+
+// TouchLastUsed record key usage, write at most once per minute so busy key
+// does not turn every request into database write
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, apiKey *entity.ApiKey, ip string) error {
+	now := time.Now()
+
+	return r.DB.WithContext(ctx).Model(&entity.ApiKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", apiKey.ID, now.Add(-time.Minute)).
+		UpdateColumns(map[string]interface{}{
+			"last_used_at": sql.NullTime{Time: now, Valid: true},
+			"last_used_ip": ip,
+		}).Error
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type ClientRepository interface {
+	FindAll(ctx context.Context) (*[]entity.Client, error)
+	FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.Client, error)
+	FindByClientID(ctx context.Context, clientID string) (*entity.Client, error)
+	Insert(ctx context.Context, client *entity.Client) error
+	Update(ctx context.Context, client *entity.Client, scopes *[]entity.Permission) error
+	UpdateSecret(ctx context.Context, client *entity.Client) error
+	Delete(ctx context.Context, client *entity.Client) error
+	FindTokenVersion(ctx context.Context, clientID string) (uint, error)
+	IncrementTokenVersion(ctx context.Context, clientID string) (uint, error)
+}
+
+type clientRepository struct {
+	*gorm.DB
+}
+
+func NewClientRepository(db *gorm.DB) ClientRepository {
+	return &clientRepository{DB: db}
+}
+
+func (r *clientRepository) FindAll(ctx context.Context) (*[]entity.Client, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var clients []entity.Client
+
+	if err := r.DB.WithContext(ctx).
+		Preload("Scopes", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name")
+		}).
+		Order("created_at DESC").
+		Find(&clients).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &clients, nil
+}
+
+func (r *clientRepository) FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.Client, error) {
+	return r.findBy(ctx, "uuid = ?", uuid)
+}
+
+func (r *clientRepository) FindByClientID(ctx context.Context, clientID string) (*entity.Client, error) {
+	return r.findBy(ctx, "client_id = ?", clientID)
+}
+
+func (r *clientRepository) findBy(ctx context.Context, query string, value interface{}) (*entity.Client, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var client entity.Client
+	result := r.DB.WithContext(ctx).Limit(1).Where(query, value).Preload("Scopes").Find(&client)
+
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("client not found")
+	}
+
+	return &client, nil
+}
+
+func (r *clientRepository) Insert(ctx context.Context, client *entity.Client) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Create(client).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// Update change client name and replace its scopes in a single transaction
+func (r *clientRepository) Update(ctx context.Context, client *entity.Client, scopes *[]entity.Permission) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(client).Where("id = ?", client.ID).Update("name", client.Name).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(client).Association("Scopes").Replace(scopes)
+	})
+
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *clientRepository) UpdateSecret(ctx context.Context, client *entity.Client) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.Client{}).Where("id = ?", client.ID).
+		UpdateColumn("secret_hash", client.SecretHash).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *clientRepository) Delete(ctx context.Context, client *entity.Client) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Where("id = ?", client.ID).Delete(client).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *clientRepository) FindTokenVersion(ctx context.Context, clientID string) (uint, error) {
+	var client entity.Client
+
+	if result := r.DB.WithContext(ctx).Select("id", "token_version").Limit(1).Where("client_id = ?", clientID).
+		Find(&client); result.Error != nil || result.RowsAffected == 0 {
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	return client.TokenVersion, nil
+}
+
+// IncrementTokenVersion bump client token version atomically and return the new version
+func (r *clientRepository) IncrementTokenVersion(ctx context.Context, clientID string) (uint, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.Client{}).Where("client_id = ?", clientID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return 0, err
+	}
+
+	return r.FindTokenVersion(ctx, clientID)
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryRepository interface {
+	FindRecentByUserID(ctx context.Context, userID uint, limit int) ([]entity.PasswordHistory, error)
+	Insert(ctx context.Context, passwordHistory *entity.PasswordHistory) error
+	PruneByUserID(ctx context.Context, userID uint, keep int) error
+}
+
+type passwordHistoryRepository struct {
+	*gorm.DB
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB) PasswordHistoryRepository {
+	return &passwordHistoryRepository{DB: db}
+}
+
+// FindRecentByUserID return the latest password of user, newest first
+func (r *passwordHistoryRepository) FindRecentByUserID(ctx context.Context, userID uint, limit int) ([]entity.PasswordHistory, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var histories []entity.PasswordHistory
+	if err := r.DB.WithContext(ctx).Where("user_id = ?", userID).
+		Order("id DESC").Limit(limit).Find(&histories).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return histories, nil
+}
+
+func (r *passwordHistoryRepository) Insert(ctx context.Context, passwordHistory *entity.PasswordHistory) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Create(passwordHistory).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// PruneByUserID delete history of user beyond the latest keep entries
+func (r *passwordHistoryRepository) PruneByUserID(ctx context.Context, userID uint, keep int) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var cutoff []uint
+	if err := r.DB.WithContext(ctx).Model(&entity.PasswordHistory{}).Where("user_id = ?", userID).
+		Order("id DESC").Offset(keep-1).Limit(1).Pluck("id", &cutoff).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	// Fewer entries than kept, nothing to prune
+	if len(cutoff) == 0 {
+		return nil
+	}
+
+	if err := r.DB.WithContext(ctx).Where("user_id = ? AND id < ?", userID, cutoff[0]).
+		Delete(&entity.PasswordHistory{}).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
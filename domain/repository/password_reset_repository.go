@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	FindValidByTokenHash(ctx context.Context, tokenHash string) (*entity.PasswordReset, error)
+	Insert(ctx context.Context, passwordReset *entity.PasswordReset) error
+	MarkUsed(ctx context.Context, passwordReset *entity.PasswordReset) error
+	DeleteUnusedByUserID(ctx context.Context, userID uint) error
+}
+
+type passwordResetRepository struct {
+	*gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{DB: db}
+}
+
+// FindValidByTokenHash only return reset request that not used and not expired yet
+func (r *passwordResetRepository) FindValidByTokenHash(ctx context.Context, tokenHash string) (*entity.PasswordReset, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var passwordReset entity.PasswordReset
+	result := r.DB.WithContext(ctx).Limit(1).
+		Where("token_hash = ? AND used_at IS NULL AND expired_at > ?", tokenHash, time.Now()).
+		Find(&passwordReset)
+
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("password reset token not found")
+	}
+
+	return &passwordReset, nil
+}
+
+func (r *passwordResetRepository) Insert(ctx context.Context, passwordReset *entity.PasswordReset) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Create(passwordReset).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// MarkUsed consume the token, failing when it already consumed by concurrent request
+func (r *passwordResetRepository) MarkUsed(ctx context.Context, passwordReset *entity.PasswordReset) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	result := r.DB.WithContext(ctx).Model(&entity.PasswordReset{}).
+		Where("id = ? AND used_at IS NULL", passwordReset.ID).
+		Update("used_at", sql.NullTime{Time: time.Now(), Valid: true})
+
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("password reset token already used")
+	}
+
+	return nil
+}
+
+func (r *passwordResetRepository) DeleteUnusedByUserID(ctx context.Context, userID uint) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Where("user_id = ? AND used_at IS NULL", userID).Delete(&entity.PasswordReset{}).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
@@ -2,11 +2,13 @@ package repository
 
 import (
 	"context"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
 	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
 	"gorm.io/gorm"
 )
 
@@ -21,6 +23,7 @@ type PermissionRepository interface {
 	Count(ctx context.Context, query *model.QueryGet) int64
 	CountUnscoped(ctx context.Context, query *model.QueryGet) int64
 	NameExist(ctx context.Context, permission *entity.Permission) bool
+	FindImplicationGraph(ctx context.Context) (map[string][]string, error)
 }
 
 type permissionRepository struct {
@@ -40,6 +43,9 @@ func (r *permissionRepository) FindByID(ctx context.Context, id uint) (*entity.P
 		Preload("Module", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "name").Unscoped()
 		}).
+		Preload("Implies", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "uuid", "name", "module_id")
+		}).
 		Find(&permission); result.Error != nil || result.RowsAffected == 0 {
 		logData.Message = "Not Passed"
 		logData.Err = result.Error
@@ -188,14 +194,25 @@ func (r *permissionRepository) Insert(ctx context.Context, permission *entity.Pe
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
-	return r.DB.WithContext(ctx).Create(permission).Error
+	// Implied permissions already exist, only the permission implications row is created
+	return r.DB.WithContext(ctx).Omit("Implies.*").Create(permission).Error
 }
 
+// Update change permission data and replace its implied permissions in a single transaction
 func (r *permissionRepository) Update(ctx context.Context, permission *entity.Permission) error {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
-	if err := r.DB.WithContext(ctx).Where("id = ?", permission.ID).Updates(permission).Error; err != nil {
+	implies := permission.Implies
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Implies").Where("id = ?", permission.ID).Updates(permission).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(permission).Omit("Implies.*").Association("Implies").Replace(implies)
+	})
+
+	if err != nil {
 		logData.Message = "Not Passed"
 		logData.Err = err
 		return err
@@ -235,3 +252,57 @@ func (r *permissionRepository) NameExist(ctx context.Context, permission *entity
 
 	return totalData != 0
 }
+
+// FindImplicationGraph map every permission name to names it directly implies, built from declared
+// implications and from wildcard permission which implies every permission of its module
+func (r *permissionRepository) FindImplicationGraph(ctx context.Context) (map[string][]string, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	graph := map[string][]string{}
+
+	var implications []struct {
+		Name    string
+		Implied string
+	}
+	if err := r.DB.WithContext(ctx).Table(constant.TABLE_PERMISSION_IMPLICATION).
+		Select("permission.name AS name", "implied.name AS implied").
+		Joins("JOIN " + constant.TABLE_PERMISSION + " permission ON permission.id = " + constant.TABLE_PERMISSION_IMPLICATION + ".permission_id AND permission.deleted_at IS NULL").
+		Joins("JOIN " + constant.TABLE_PERMISSION + " implied ON implied.id = " + constant.TABLE_PERMISSION_IMPLICATION + ".implied_id AND implied.deleted_at IS NULL").
+		Scan(&implications).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	for _, implication := range implications {
+		graph[implication.Name] = append(graph[implication.Name], implication.Implied)
+	}
+
+	var permissions []struct {
+		Name   string
+		Module string
+	}
+	if err := r.DB.WithContext(ctx).Model(&entity.Permission{}).
+		Select(constant.TABLE_PERMISSION+".name AS name", constant.TABLE_MODULE+".name AS module").
+		Joins("JOIN " + constant.TABLE_MODULE + " ON " + constant.TABLE_MODULE + ".id = " + constant.TABLE_PERMISSION + ".module_id AND " + constant.TABLE_MODULE + ".deleted_at IS NULL").
+		Scan(&permissions).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	byModule := map[string][]string{}
+	for _, permission := range permissions {
+		module := strings.ToLower(permission.Module)
+		byModule[module] = append(byModule[module], permission.Name)
+	}
+
+	for _, permission := range permissions {
+		if module, found := strings.CutSuffix(permission.Name, constant.PermissionWildcardSuffix); found {
+			graph[permission.Name] = append(graph[permission.Name], byModule[strings.ToLower(module)]...)
+		}
+	}
+
+	return graph, nil
+}
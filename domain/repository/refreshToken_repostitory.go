@@ -2,21 +2,31 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
 	"gorm.io/gorm"
 )
 
 type RefreshTokenRepository interface {
-	FindByToken(ctx context.Context, token string) (*entity.RefreshToken, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
 	FindAllByUserID(ctx context.Context, userID uint) ([]entity.RefreshToken, error)
 	Insert(ctx context.Context, token *entity.RefreshToken) error
-	RevokeByToken(ctx context.Context, token string) error
+	RevokeByTokenHash(ctx context.Context, tokenHash string) error
+	Rotate(ctx context.Context, token *entity.RefreshToken) error
+	RevokeFamily(ctx context.Context, token *entity.RefreshToken) error
+	RevokeSession(ctx context.Context, userID uint, sessionID uuid.UUID) error
 	RevokeAllByUserID(ctx context.Context, userID uint) error
 	CountTokensByUserID(ctx context.Context, userID uint) (int64, error)
 	DeleteExpiredTokens(ctx context.Context) error
+	FindLiveAccessBySession(ctx context.Context, userID uint, sessionID uuid.UUID) ([]entity.RefreshToken, error)
+	FindLiveAccessByUserID(ctx context.Context, userID uint) ([]entity.RefreshToken, error)
+	FindLiveAccessByRoleID(ctx context.Context, roleID uint) ([]entity.RefreshToken, error)
+	FindLiveAccessByPermissionID(ctx context.Context, permissionID uint) ([]entity.RefreshToken, error)
 }
 
 type refreshTokenRepository struct {
@@ -27,10 +37,10 @@ func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
 	return &refreshTokenRepository{DB: db}
 }
 
-func (r *refreshTokenRepository) FindByToken(ctx context.Context, token string) (*entity.RefreshToken, error) {
+func (r *refreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
 	var refreshToken entity.RefreshToken
 
-	result := r.DB.WithContext(ctx).Limit(1).Where("token = ?", token).Find(&refreshToken)
+	result := r.DB.WithContext(ctx).Limit(1).Where("token_hash = ?", tokenHash).Find(&refreshToken)
 
 	if result.RowsAffected == 0 {
 		return nil, fmt.Errorf("refresh token not found")
@@ -44,7 +54,9 @@ func (r *refreshTokenRepository) FindByToken(ctx context.Context, token string)
 func (r *refreshTokenRepository) FindAllByUserID(ctx context.Context, userID uint) ([]entity.RefreshToken, error) {
 	var tokens []entity.RefreshToken
 
-	if err := r.DB.WithContext(ctx).Where("user_id = ?", userID).Find(&tokens).Error; err != nil {
+	if err := r.DB.WithContext(ctx).Where("user_id = ? AND rotated_at IS NULL AND expired_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error; err != nil {
 		return tokens, err
 	}
 
@@ -55,8 +67,52 @@ func (r *refreshTokenRepository) Insert(ctx context.Context, token *entity.Refre
 	return r.DB.WithContext(ctx).Create(token).Error
 }
 
-func (r *refreshTokenRepository) RevokeByToken(ctx context.Context, token string) error {
-	return r.DB.WithContext(ctx).Where("token = ?", token).Delete(&entity.RefreshToken{}).Error
+func (r *refreshTokenRepository) RevokeByTokenHash(ctx context.Context, tokenHash string) error {
+	return r.DB.WithContext(ctx).Where("token_hash = ?", tokenHash).Delete(&entity.RefreshToken{}).Error
+}
+
+// Rotate mark token as already exchanged, failing when it was rotated by another request
+func (r *refreshTokenRepository) Rotate(ctx context.Context, token *entity.RefreshToken) error {
+	result := r.DB.WithContext(ctx).Model(&entity.RefreshToken{}).
+		Where("uuid = ? AND rotated_at IS NULL", token.UUID).
+		Update("rotated_at", sql.NullTime{Time: time.Now(), Valid: true})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("refresh token already rotated")
+	}
+
+	return nil
+}
+
+// RevokeFamily revoke every token that descend from the same login
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, token *entity.RefreshToken) error {
+	// Token created before family introduced only revoke itself
+	if token.FamilyID == uuid.Nil {
+		return r.DB.WithContext(ctx).Where("uuid = ?", token.UUID).Delete(&entity.RefreshToken{}).Error
+	}
+
+	return r.DB.WithContext(ctx).Where("family_id = ?", token.FamilyID).Delete(&entity.RefreshToken{}).Error
+}
+
+// RevokeSession revoke session (token family) owned by user, session id is the family id
+func (r *refreshTokenRepository) RevokeSession(ctx context.Context, userID uint, sessionID uuid.UUID) error {
+	result := r.DB.WithContext(ctx).
+		Where("user_id = ? AND (family_id = ? OR uuid = ?)", userID, sessionID, sessionID).
+		Delete(&entity.RefreshToken{})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("session not found")
+	}
+
+	return nil
 }
 
 func (r *refreshTokenRepository) RevokeAllByUserID(ctx context.Context, userID uint) error {
@@ -66,7 +122,7 @@ func (r *refreshTokenRepository) RevokeAllByUserID(ctx context.Context, userID u
 func (r *refreshTokenRepository) CountTokensByUserID(ctx context.Context, userID uint) (int64, error) {
 	var total int64
 
-	if err := r.DB.WithContext(ctx).Model(&entity.RefreshToken{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+	if err := r.DB.WithContext(ctx).Model(&entity.RefreshToken{}).Where("user_id = ? AND rotated_at IS NULL AND expired_at > ?", userID, time.Now()).Count(&total).Error; err != nil {
 		return 0, err
 	}
 
@@ -76,3 +132,64 @@ func (r *refreshTokenRepository) CountTokensByUserID(ctx context.Context, userID
 func (r *refreshTokenRepository) DeleteExpiredTokens(ctx context.Context) error {
 	return r.DB.WithContext(ctx).Where("expired_at < ?", time.Now()).Delete(&entity.RefreshToken{}).Error
 }
+
+// FindLiveAccessBySession find token in session (family) whose access token not yet expired
+func (r *refreshTokenRepository) FindLiveAccessBySession(ctx context.Context, userID uint, sessionID uuid.UUID) ([]entity.RefreshToken, error) {
+	var tokens []entity.RefreshToken
+
+	err := r.liveAccess(ctx).
+		Where("user_id = ? AND (family_id = ? OR uuid = ?)", userID, sessionID, sessionID).
+		Find(&tokens).Error
+
+	return tokens, err
+}
+
+// FindLiveAccessByUserID find token of user whose access token not yet expired
+func (r *refreshTokenRepository) FindLiveAccessByUserID(ctx context.Context, userID uint) ([]entity.RefreshToken, error) {
+	var tokens []entity.RefreshToken
+
+	err := r.liveAccess(ctx).Where("user_id = ?", userID).Find(&tokens).Error
+
+	return tokens, err
+}
+
+// FindLiveAccessByRoleID find token of every user assigned to role whose access token not yet expired
+func (r *refreshTokenRepository) FindLiveAccessByRoleID(ctx context.Context, roleID uint) ([]entity.RefreshToken, error) {
+	var tokens []entity.RefreshToken
+
+	err := r.liveAccess(ctx).
+		Where("user_id IN (?)", r.DB.Table(constant.TABLE_USER_ROLE).Select("user_id").Where("role_id = ?", roleID)).
+		Find(&tokens).Error
+
+	return tokens, err
+}
+
+// FindLiveAccessByPermissionID find token of every user having a role with the permission,
+// directly or inherited, and access token not yet expired
+func (r *refreshTokenRepository) FindLiveAccessByPermissionID(ctx context.Context, permissionID uint) ([]entity.RefreshToken, error) {
+	var tokens []entity.RefreshToken
+
+	var roleIDs []uint
+	if err := r.DB.WithContext(ctx).Table(constant.TABLE_ROLE_PERMISSION).
+		Where("permission_id = ?", permissionID).Pluck("role_id", &roleIDs).Error; err != nil {
+		return nil, err
+	}
+
+	// Role inheriting the permission from its ancestor has it as well
+	descendantIDs, err := roleDescendantIDs(ctx, r.DB, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := r.DB.Table(constant.TABLE_USER_ROLE).Select("user_id").Where("role_id IN ?", append(roleIDs, descendantIDs...))
+
+	err = r.liveAccess(ctx).Where("user_id IN (?)", userIDs).Find(&tokens).Error
+
+	return tokens, err
+}
+
+func (r *refreshTokenRepository) liveAccess(ctx context.Context) *gorm.DB {
+	return r.DB.WithContext(ctx).
+		Select("uuid", "user_id", "access_jti", "access_expired_at").
+		Where("access_jti <> '' AND access_expired_at > ?", time.Now())
+}
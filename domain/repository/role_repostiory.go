@@ -2,11 +2,13 @@ package repository
 
 import (
 	"context"
+	"sort"
 
 	"github.com/google/uuid"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
 	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
 	"gorm.io/gorm"
 )
 
@@ -15,6 +17,8 @@ type RoleRepository interface {
 	FindByID(ctx context.Context, id uint) (*entity.Role, error)
 	FindByIDUnscoped(ctx context.Context, id uint) (*entity.Role, error)
 	FindByUUID(ctx context.Context, uuid uuid.UUID) (*entity.Role, error)
+	FindByName(ctx context.Context, name string) (*entity.Role, error)
+	FindByIDs(ctx context.Context, ids []uint) (*[]entity.Role, error)
 	FindAll(ctx context.Context, query *model.QueryGet) (*[]entity.Role, error)
 	Count(ctx context.Context, query *model.QueryGet) int64
 	CountUnscoped(ctx context.Context, query *model.QueryGet) int64
@@ -23,6 +27,14 @@ type RoleRepository interface {
 	Delete(ctx context.Context, role *entity.Role) error
 	NameExist(ctx context.Context, role *entity.Role) bool
 	ReplacePermissionsWithTransaction(ctx context.Context, tx *gorm.DB, role *entity.Role, permissions *[]entity.Permission) error
+	ReplaceParentsWithTransaction(ctx context.Context, tx *gorm.DB, role *entity.Role, parents *[]entity.Role) error
+	FindAncestors(ctx context.Context, id uint) (*[]entity.Role, error)
+	FindAncestorIDs(ctx context.Context, ids []uint) ([]uint, error)
+	FindDescendantIDs(ctx context.Context, id uint) ([]uint, error)
+	FindTokenVersion(ctx context.Context, id uint) (uint, error)
+	IncrementTokenVersion(ctx context.Context, id uint) (uint, error)
+	FindIDsByPermissionID(ctx context.Context, permissionID uint) ([]uint, error)
+	FindPermissionNamesByID(ctx context.Context, id uint) ([]string, error)
 }
 
 type roleRepository struct {
@@ -43,6 +55,9 @@ func (r *roleRepository) FindByID(ctx context.Context, id uint) (*entity.Role, e
 
 	var role entity.Role
 	if result := r.DB.WithContext(ctx).Limit(1).Where("id = ?", id).
+		Preload("Parents", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "uuid", "name")
+		}).
 		Preload("Permissions", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "name", "uuid", "module_id")
 		}).
@@ -58,6 +73,40 @@ func (r *roleRepository) FindByID(ctx context.Context, id uint) (*entity.Role, e
 	return &role, nil
 }
 
+// FindByIDs find every existing role of the given ids, missing id is simply skipped
+func (r *roleRepository) FindByIDs(ctx context.Context, ids []uint) (*[]entity.Role, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	roles := []entity.Role{}
+	if err := r.DB.WithContext(ctx).Where("id IN ?", ids).Find(&roles).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &roles, nil
+}
+
+func (r *roleRepository) FindByName(ctx context.Context, name string) (*entity.Role, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var role entity.Role
+	result := r.DB.WithContext(ctx).Limit(1).Where("name = ?", name).Find(&role)
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &role, nil
+}
+
 func (r *roleRepository) FindByIDUnscoped(ctx context.Context, id uint) (*entity.Role, error) {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -193,7 +242,8 @@ func (r *roleRepository) Insert(ctx context.Context, role *entity.Role) error {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
-	return r.DB.WithContext(ctx).Create(role).Error
+	// Parents already exist, only the role parents row is created
+	return r.DB.WithContext(ctx).Omit("Parents.*").Create(role).Error
 }
 
 func (r *roleRepository) Update(ctx context.Context, role *entity.Role) error {
@@ -213,7 +263,9 @@ func (r *roleRepository) UpdateWithTransaction(ctx context.Context, tx *gorm.DB,
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
-	if err := tx.WithContext(ctx).Where("id = ?", role.ID).Updates(role).Error; err != nil {
+	// Select is used so boolean flag could be updated back to false
+	if err := tx.WithContext(ctx).Model(role).Where("id = ?", role.ID).
+		Select("name", "is_admin", "require_two_factor").Updates(role).Error; err != nil {
 		logData.Err = err
 		logData.Message = "Not Passed"
 		return err
@@ -272,3 +324,238 @@ func (r *roleRepository) ReplacePermissionsWithTransaction(ctx context.Context,
 	return nil
 
 }
+
+func (r *roleRepository) ReplaceParentsWithTransaction(ctx context.Context, tx *gorm.DB, role *entity.Role, parents *[]entity.Role) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := tx.Model(role).Omit("Parents.*").Association("Parents").Replace(parents); err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// FindAncestors find every role the given role inherit from along with their permissions,
+// nearest ancestor come first
+func (r *roleRepository) FindAncestors(ctx context.Context, id uint) (*[]entity.Role, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	ids, err := roleAncestorIDs(ctx, r.DB, []uint{id})
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	roles := []entity.Role{}
+	if len(ids) == 0 {
+		return &roles, nil
+	}
+
+	if err := r.DB.WithContext(ctx).Where("id IN ?", ids).
+		Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name", "uuid", "module_id")
+		}).
+		Preload("Permissions.Module", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name").Unscoped()
+		}).
+		Find(&roles).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	order := make(map[uint]int, len(ids))
+	for i, id := range ids {
+		order[id] = i
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return order[roles[i].ID] < order[roles[j].ID]
+	})
+
+	return &roles, nil
+}
+
+// FindAncestorIDs find id of every role the given roles inherit from
+func (r *roleRepository) FindAncestorIDs(ctx context.Context, ids []uint) ([]uint, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	ancestorIDs, err := roleAncestorIDs(ctx, r.DB, ids)
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return ancestorIDs, nil
+}
+
+// FindDescendantIDs find id of every role inheriting from the given role
+func (r *roleRepository) FindDescendantIDs(ctx context.Context, id uint) ([]uint, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	descendantIDs, err := roleDescendantIDs(ctx, r.DB, []uint{id})
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return descendantIDs, nil
+}
+
+func (r *roleRepository) FindTokenVersion(ctx context.Context, id uint) (uint, error) {
+	var role entity.Role
+
+	if result := r.DB.WithContext(ctx).Select("id", "token_version").Limit(1).Where("id = ?", id).
+		Find(&role); result.Error != nil || result.RowsAffected == 0 {
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	return role.TokenVersion, nil
+}
+
+// IncrementTokenVersion bump role token version atomically and return the new version
+func (r *roleRepository) IncrementTokenVersion(ctx context.Context, id uint) (uint, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.Role{}).Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+		logData.Err = err
+		logData.Message = "Not Passed"
+		return 0, err
+	}
+
+	return r.FindTokenVersion(ctx, id)
+}
+
+// FindIDsByPermissionID find id of every role that has the permission, either directly
+// or inherited from its ancestor
+func (r *roleRepository) FindIDsByPermissionID(ctx context.Context, permissionID uint) ([]uint, error) {
+	var ids []uint
+
+	if err := r.DB.WithContext(ctx).Model(&entity.RolePermission{}).
+		Where("permission_id = ?", permissionID).
+		Pluck("role_id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	descendantIDs, err := roleDescendantIDs(ctx, r.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ids, descendantIDs...), nil
+}
+
+// FindPermissionNamesByID find name of every permission assigned to role or inherited from its ancestor
+func (r *roleRepository) FindPermissionNamesByID(ctx context.Context, id uint) ([]string, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	ids, err := roleAncestorIDs(ctx, r.DB, []uint{id})
+	if err != nil {
+		logData.Err = err
+		logData.Message = "Not Passed"
+		return nil, err
+	}
+
+	names := []string{}
+
+	if err := r.DB.WithContext(ctx).Model(&entity.Permission{}).
+		Joins("JOIN "+constant.TABLE_ROLE_PERMISSION+" ON "+constant.TABLE_ROLE_PERMISSION+".permission_id = "+constant.TABLE_PERMISSION+".id").
+		Where(constant.TABLE_ROLE_PERMISSION+".role_id IN ?", append(ids, id)).
+		Distinct().
+		Pluck(constant.TABLE_PERMISSION+".name", &names).Error; err != nil {
+		logData.Err = err
+		logData.Message = "Not Passed"
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// roleAncestorIDs walk role parents upward from the given roles, nearest first
+func roleAncestorIDs(ctx context.Context, db *gorm.DB, ids []uint) ([]uint, error) {
+	return walkRoleParents(ctx, db, ids, "role_id", "parent_id")
+}
+
+// roleDescendantIDs walk role parents downward from the given roles, nearest first
+func roleDescendantIDs(ctx context.Context, db *gorm.DB, ids []uint) ([]uint, error) {
+	return walkRoleParents(ctx, db, ids, "parent_id", "role_id")
+}
+
+// walkRoleParents follow role parents level by level from one column to the other, deleted
+// role is skipped and visited role is never walked twice so stored cycle could not loop forever
+func walkRoleParents(ctx context.Context, db *gorm.DB, ids []uint, from string, to string) ([]uint, error) {
+	visited := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		visited[id] = struct{}{}
+	}
+
+	found := []uint{}
+	frontier := ids
+	for len(frontier) != 0 {
+		var next []uint
+		if err := db.WithContext(ctx).Table(constant.TABLE_ROLE_PARENT).
+			Joins("JOIN "+constant.TABLE_ROLE+" ON "+constant.TABLE_ROLE+".id = "+constant.TABLE_ROLE_PARENT+"."+to+
+				" AND "+constant.TABLE_ROLE+".deleted_at IS NULL").
+			Where(constant.TABLE_ROLE_PARENT+"."+from+" IN ?", frontier).
+			Pluck(constant.TABLE_ROLE_PARENT+"."+to, &next).Error; err != nil {
+			return nil, err
+		}
+
+		frontier = nil
+		for _, id := range next {
+			if _, exists := visited[id]; exists {
+				continue
+			}
+
+			visited[id] = struct{}{}
+			found = append(found, id)
+			frontier = append(frontier, id)
+		}
+	}
+
+	return found, nil
+}
+
+// loadInheritedPermissions fill inherited permissions of role from all of its ancestors,
+// permission the role already has directly is left out
+func loadInheritedPermissions(ctx context.Context, db *gorm.DB, role *entity.Role) error {
+	ancestorIDs, err := roleAncestorIDs(ctx, db, []uint{role.ID})
+	if err != nil || len(ancestorIDs) == 0 {
+		return err
+	}
+
+	var permissions []entity.Permission
+	if err := db.WithContext(ctx).
+		Where("id IN (?)", db.Table(constant.TABLE_ROLE_PERMISSION).Select("permission_id").Where("role_id IN ?", ancestorIDs)).
+		Find(&permissions).Error; err != nil {
+		return err
+	}
+
+	direct := make(map[uint]struct{}, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		direct[permission.ID] = struct{}{}
+	}
+
+	role.InheritedPermissions = []entity.Permission{}
+	for _, permission := range permissions {
+		if _, exists := direct[permission.ID]; !exists {
+			role.InheritedPermissions = append(role.InheritedPermissions, permission)
+		}
+	}
+
+	return nil
+}
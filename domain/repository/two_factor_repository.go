@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository interface {
+	FindByUserID(ctx context.Context, userID uint) (*entity.UserTwoFactor, error)
+	Insert(ctx context.Context, twoFactor *entity.UserTwoFactor) error
+	Update(ctx context.Context, twoFactor *entity.UserTwoFactor) error
+	DeleteByUserID(ctx context.Context, userID uint) error
+	ReplaceRecoveryCodes(ctx context.Context, twoFactor *entity.UserTwoFactor, codes *[]entity.TwoFactorRecoveryCode) error
+	MarkRecoveryCodeUsed(ctx context.Context, code *entity.TwoFactorRecoveryCode) error
+}
+
+type twoFactorRepository struct {
+	*gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{DB: db}
+}
+
+func (r *twoFactorRepository) FindByUserID(ctx context.Context, userID uint) (*entity.UserTwoFactor, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var twoFactor entity.UserTwoFactor
+	result := r.DB.WithContext(ctx).Limit(1).Where("user_id = ?", userID).
+		Preload("RecoveryCodes", func(db *gorm.DB) *gorm.DB {
+			return db.Where("used_at IS NULL")
+		}).
+		Find(&twoFactor)
+
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("two factor data not found")
+	}
+
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepository) Insert(ctx context.Context, twoFactor *entity.UserTwoFactor) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Create(twoFactor).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) Update(ctx context.Context, twoFactor *entity.UserTwoFactor) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	// Select is used so zero value (e.g. cleared enabled_at) still persisted
+	if err := r.DB.WithContext(ctx).Model(twoFactor).
+		Select("secret", "last_used_step", "enabled_at").
+		Updates(twoFactor).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		subQuery := tx.Model(&entity.UserTwoFactor{}).Select("id").Where("user_id = ?", userID)
+		if err := tx.Where("user_two_factor_id IN (?)", subQuery).Delete(&entity.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", userID).Delete(&entity.UserTwoFactor{}).Error
+	})
+
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, twoFactor *entity.UserTwoFactor, codes *[]entity.TwoFactorRecoveryCode) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_two_factor_id = ?", twoFactor.ID).Delete(&entity.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		for i := range *codes {
+			(*codes)[i].UserTwoFactorID = twoFactor.ID
+		}
+
+		return tx.Create(codes).Error
+	})
+
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) MarkRecoveryCodeUsed(ctx context.Context, code *entity.TwoFactorRecoveryCode) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	// Only mark unused code, so the same code could not be consumed twice concurrently
+	result := r.DB.WithContext(ctx).Model(&entity.TwoFactorRecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", code.ID).
+		Update("used_at", sql.NullTime{Time: time.Now(), Valid: true})
+
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("recovery code already used")
+	}
+
+	return nil
+}
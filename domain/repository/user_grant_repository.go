@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type UserGrantRepository interface {
+	FindAllByUserID(ctx context.Context, userID uint) (*[]entity.UserGrant, error)
+	FindByUUID(ctx context.Context, userID uint, uuid uuid.UUID) (*entity.UserGrant, error)
+	FindDueActivation(ctx context.Context, now time.Time) (*[]entity.UserGrant, error)
+	FindDueExpiry(ctx context.Context, now time.Time) (*[]entity.UserGrant, error)
+	Insert(ctx context.Context, grant *entity.UserGrant) error
+	Delete(ctx context.Context, grant *entity.UserGrant) error
+	MarkActivated(ctx context.Context, ids []uint, now time.Time) error
+	MarkExpired(ctx context.Context, ids []uint, now time.Time) error
+}
+
+type userGrantRepository struct {
+	*gorm.DB
+}
+
+func NewUserGrantRepository(db *gorm.DB) UserGrantRepository {
+	return &userGrantRepository{DB: db}
+}
+
+func (r *userGrantRepository) FindAllByUserID(ctx context.Context, userID uint) (*[]entity.UserGrant, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var grants []entity.UserGrant
+
+	if err := r.DB.WithContext(ctx).Where("user_id = ?", userID).
+		Preload("Role", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name").Unscoped()
+		}).
+		Preload("Permission", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name").Unscoped()
+		}).
+		Order("valid_from DESC").
+		Find(&grants).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &grants, nil
+}
+
+func (r *userGrantRepository) FindByUUID(ctx context.Context, userID uint, uuid uuid.UUID) (*entity.UserGrant, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var grant entity.UserGrant
+	result := r.DB.WithContext(ctx).Limit(1).Where("user_id = ? AND uuid = ?", userID, uuid).
+		Preload("Role", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name").Unscoped()
+		}).
+		Preload("Permission", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name").Unscoped()
+		}).
+		Find(&grant)
+
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("grant not found")
+	}
+
+	return &grant, nil
+}
+
+// FindDueActivation find grant that already started but not handled by grant job yet
+func (r *userGrantRepository) FindDueActivation(ctx context.Context, now time.Time) (*[]entity.UserGrant, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var grants []entity.UserGrant
+
+	if err := r.DB.WithContext(ctx).
+		Where("activated_at IS NULL AND expired_at IS NULL AND valid_from <= ?", now).
+		Where("valid_until IS NULL OR valid_until > ?", now).
+		Find(&grants).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &grants, nil
+}
+
+// FindDueExpiry find grant that already ended but not handled by grant job yet
+func (r *userGrantRepository) FindDueExpiry(ctx context.Context, now time.Time) (*[]entity.UserGrant, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var grants []entity.UserGrant
+
+	if err := r.DB.WithContext(ctx).
+		Where("expired_at IS NULL AND valid_until IS NOT NULL AND valid_until <= ?", now).
+		Find(&grants).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &grants, nil
+}
+
+func (r *userGrantRepository) Insert(ctx context.Context, grant *entity.UserGrant) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Omit("User", "Role", "Permission").Create(grant).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *userGrantRepository) Delete(ctx context.Context, grant *entity.UserGrant) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Delete(grant).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *userGrantRepository) MarkActivated(ctx context.Context, ids []uint, now time.Time) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.UserGrant{}).Where("id IN ?", ids).
+		UpdateColumn("activated_at", now).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *userGrantRepository) MarkExpired(ctx context.Context, ids []uint, now time.Time) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.UserGrant{}).Where("id IN ?", ids).
+		UpdateColumn("expired_at", now).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
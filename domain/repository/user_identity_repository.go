@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository interface {
+	FindByProviderSubject(ctx context.Context, provider string, subject string) (*entity.UserIdentity, error)
+	Insert(ctx context.Context, identity *entity.UserIdentity) error
+}
+
+type userIdentityRepository struct {
+	*gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepository{DB: db}
+}
+
+func (r *userIdentityRepository) FindByProviderSubject(
+	ctx context.Context, provider string, subject string,
+) (*entity.UserIdentity, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var identity entity.UserIdentity
+	result := r.DB.WithContext(ctx).Limit(1).
+		Where("provider = ? AND subject = ?", provider, subject).
+		Find(&identity)
+
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("user identity not found")
+	}
+
+	return &identity, nil
+}
+
+// Insert fail when subject or user already linked for the provider, enforced by unique index
+func (r *userIdentityRepository) Insert(ctx context.Context, identity *entity.UserIdentity) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Create(identity).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
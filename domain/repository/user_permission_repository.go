@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+type UserPermissionRepository interface {
+	FindAllByUserID(ctx context.Context, userID uint) (*[]entity.UserPermission, error)
+	FindByPermissionID(ctx context.Context, userID uint, permissionID uint) (*entity.UserPermission, error)
+	Insert(ctx context.Context, override *entity.UserPermission) error
+	Update(ctx context.Context, override *entity.UserPermission) error
+	Delete(ctx context.Context, userID uint, permissionID uint) error
+}
+
+type userPermissionRepository struct {
+	*gorm.DB
+}
+
+func NewUserPermissionRepository(db *gorm.DB) UserPermissionRepository {
+	return &userPermissionRepository{DB: db}
+}
+
+func (r *userPermissionRepository) FindAllByUserID(ctx context.Context, userID uint) (*[]entity.UserPermission, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var overrides []entity.UserPermission
+
+	if err := r.DB.WithContext(ctx).Where("user_id = ?", userID).
+		Preload("Permission", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name").Unscoped()
+		}).
+		Order("created_at DESC").
+		Find(&overrides).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return nil, err
+	}
+
+	return &overrides, nil
+}
+
+func (r *userPermissionRepository) FindByPermissionID(ctx context.Context, userID uint, permissionID uint) (*entity.UserPermission, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var override entity.UserPermission
+	result := r.DB.WithContext(ctx).Limit(1).Where("user_id = ? AND permission_id = ?", userID, permissionID).
+		Preload("Permission", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name").Unscoped()
+		}).
+		Find(&override)
+
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("permission override not found")
+	}
+
+	return &override, nil
+}
+
+func (r *userPermissionRepository) Insert(ctx context.Context, override *entity.UserPermission) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Omit("User", "Permission").Create(override).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *userPermissionRepository) Update(ctx context.Context, override *entity.UserPermission) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.UserPermission{}).
+		Where("user_id = ? AND permission_id = ?", override.UserID, override.PermissionID).
+		Updates(map[string]interface{}{
+			"effect":     override.Effect,
+			"reason":     override.Reason,
+			"granted_by": override.GrantedBy,
+		}).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+func (r *userPermissionRepository) Delete(ctx context.Context, userID uint, permissionID uint) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	result := r.DB.WithContext(ctx).Where("user_id = ? AND permission_id = ?", userID, permissionID).
+		Delete(&entity.UserPermission{})
+	if result.Error != nil {
+		logData.Message = "Not Passed"
+		logData.Err = result.Error
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("permission override not found")
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
@@ -20,10 +21,17 @@ type UserRepository interface {
 	Insert(ctx context.Context, user *entity.User) error
 	Update(ctx context.Context, user *entity.User) error
 	UpdateWithTransaction(ctx context.Context, tx *gorm.DB, user *entity.User) error
+	UpdateWithRoles(ctx context.Context, user *entity.User) error
+	ResetValidation(ctx context.Context, id uint) error
+	Approve(ctx context.Context, id uint) error
 	Delete(ctx context.Context, user *entity.User) error
 	EmailExist(ctx context.Context, user *entity.User) bool
 	UsernameExist(ctx context.Context, user *entity.User) bool
 	FindByUsernameOrEmail(ctx context.Context, usernameOrEmail string) (*entity.User, error)
+	FindByIDWithRole(ctx context.Context, id uint) (*entity.User, error)
+	FindByEmailWithRole(ctx context.Context, email string) (*entity.User, error)
+	FindTokenVersion(ctx context.Context, id uint) (uint, error)
+	IncrementTokenVersion(ctx context.Context, id uint) (uint, error)
 }
 
 type userRepository struct {
@@ -45,7 +53,9 @@ func (r *userRepository) FindByID(ctx context.Context, id uint) (*entity.User, e
 		Preload("Role", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "name").Unscoped()
 		}).
-		Preload("Profile").
+		Preload("Roles", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name")
+		}).
 		Find(&user)
 
 	if result.Error != nil || result.RowsAffected == 0 {
@@ -66,7 +76,9 @@ func (r *userRepository) FindByUUID(ctx context.Context, uuid uuid.UUID) (*entit
 		Preload("Role", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "name").Unscoped()
 		}).
-		Preload("Profile").
+		Preload("Roles", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name")
+		}).
 		Find(&user); result.Error != nil || result.RowsAffected == 0 {
 		logData.Message = "Not Passed"
 		logData.Err = result.Error
@@ -85,6 +97,9 @@ func (r *userRepository) FindAll(ctx context.Context, query *model.QueryGet) (*[
 		Joins("JOIN roles on roles.id = users.role_id").
 		Preload("Role", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "name").Unscoped()
+		}).
+		Preload("Roles", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name")
 		})
 
 	var allowedFields = map[string]string{
@@ -186,7 +201,8 @@ func (r *userRepository) Insert(ctx context.Context, user *entity.User) error {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
-	if err := r.DB.WithContext(ctx).Create(user).Error; err != nil {
+	// Assigned roles already exist, only the user roles row is created
+	if err := r.DB.WithContext(ctx).Omit("Roles.*").Create(user).Error; err != nil {
 		logData.Message = "Not Passed"
 		logData.Err = err
 		return err
@@ -206,6 +222,36 @@ func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
 	return nil
 }
 
+// ResetValidation mark user email as not verified, Update could not set it since null is skipped
+func (r *userRepository) ResetValidation(ctx context.Context, id uint) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.User{}).Where("id = ?", id).
+		Update("validated_at", nil).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
+// Approve let self registered user login, Update could not set it since false is skipped
+func (r *userRepository) Approve(ctx context.Context, id uint) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.User{}).Where("id = ?", id).
+		Update("pending_approval", false).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
 func (r *userRepository) UpdateWithTransaction(ctx context.Context, tx *gorm.DB, user *entity.User) error {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -218,6 +264,29 @@ func (r *userRepository) UpdateWithTransaction(ctx context.Context, tx *gorm.DB,
 	return nil
 }
 
+// UpdateWithRoles change user data and replace its assigned roles in a single transaction
+func (r *userRepository) UpdateWithRoles(ctx context.Context, user *entity.User) error {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	roles := user.Roles
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Roles").Where("id = ?", user.ID).Updates(user).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(user).Omit("Roles.*").Association("Roles").Replace(roles)
+	})
+
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	return nil
+}
+
 func (r *userRepository) Delete(ctx context.Context, user *entity.User) error {
 	logData := helpers.CreateLog(r)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -272,7 +341,9 @@ func (r *userRepository) UsernameExist(ctx context.Context, user *entity.User) b
 func (r *userRepository) FindByUsernameOrEmail(ctx context.Context, usernameOrEmail string) (*entity.User, error) {
 	var user entity.User
 
-	result := r.DB.WithContext(ctx).Limit(1).Where("username = ?", usernameOrEmail).Or("email = ?", usernameOrEmail).Preload("Role").Preload("Role.Permissions").Find(&user)
+	result := r.DB.WithContext(ctx).Limit(1).Where("username = ?", usernameOrEmail).Or("email = ?", usernameOrEmail).
+		Preload("Role").Preload("Role.Permissions").Preload("Roles").Preload("Roles.Permissions").
+		Preload("PermissionOverrides.Permission").Find(&user)
 
 	if result.RowsAffected == 0 {
 		return nil, fmt.Errorf("user data not found")
@@ -281,5 +352,140 @@ func (r *userRepository) FindByUsernameOrEmail(ctx context.Context, usernameOrEm
 		return nil, result.Error
 	}
 
+	if err := loadUserInheritedPermissions(ctx, r.DB, &user); err != nil {
+		return nil, err
+	}
+
+	if err := loadUserActiveGrants(ctx, r.DB, &user); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
+
+// FindByIDWithRole find user along with complete roles and their permissions,
+// used when generating token or checking role based policy
+func (r *userRepository) FindByIDWithRole(ctx context.Context, id uint) (*entity.User, error) {
+	var user entity.User
+
+	result := r.DB.WithContext(ctx).Limit(1).Where("id = ?", id).
+		Preload("Role").Preload("Role.Permissions").Preload("Roles").Preload("Roles.Permissions").
+		Preload("PermissionOverrides.Permission").Find(&user)
+
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("user data not found")
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if err := loadUserInheritedPermissions(ctx, r.DB, &user); err != nil {
+		return nil, err
+	}
+
+	if err := loadUserActiveGrants(ctx, r.DB, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// FindByEmailWithRole find user by email along with complete roles, used when linking
+// external identity to existing user
+func (r *userRepository) FindByEmailWithRole(ctx context.Context, email string) (*entity.User, error) {
+	var user entity.User
+
+	result := r.DB.WithContext(ctx).Limit(1).Where("email = ?", email).
+		Preload("Role").Preload("Role.Permissions").Preload("Roles").Preload("Roles.Permissions").
+		Preload("PermissionOverrides.Permission").Find(&user)
+
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("user data not found")
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if err := loadUserInheritedPermissions(ctx, r.DB, &user); err != nil {
+		return nil, err
+	}
+
+	if err := loadUserActiveGrants(ctx, r.DB, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) FindTokenVersion(ctx context.Context, id uint) (uint, error) {
+	var user entity.User
+
+	result := r.DB.WithContext(ctx).Select("id", "token_version").Limit(1).Where("id = ?", id).Find(&user)
+
+	if result.RowsAffected == 0 {
+		return 0, fmt.Errorf("user data not found")
+	}
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return user.TokenVersion, nil
+}
+
+// IncrementTokenVersion bump user token version atomically and return the new version
+func (r *userRepository) IncrementTokenVersion(ctx context.Context, id uint) (uint, error) {
+	logData := helpers.CreateLog(r)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := r.DB.WithContext(ctx).Model(&entity.User{}).Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return 0, err
+	}
+
+	return r.FindTokenVersion(ctx, id)
+}
+
+// loadUserInheritedPermissions fill inherited permissions of every role assigned to user
+func loadUserInheritedPermissions(ctx context.Context, db *gorm.DB, user *entity.User) error {
+	if err := loadInheritedPermissions(ctx, db, &user.Role); err != nil {
+		return err
+	}
+
+	for i := range user.Roles {
+		if err := loadInheritedPermissions(ctx, db, &user.Roles[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadUserActiveGrants fill grants of user in effect right now, along with granted role (including
+// its inherited permissions) or granted permission
+func loadUserActiveGrants(ctx context.Context, db *gorm.DB, user *entity.User) error {
+	now := time.Now()
+
+	var grants []entity.UserGrant
+	if err := db.WithContext(ctx).
+		Where("user_id = ? AND valid_from <= ? AND (valid_until IS NULL OR valid_until > ?)", user.ID, now, now).
+		Preload("Role").Preload("Role.Permissions").Preload("Permission").
+		Find(&grants).Error; err != nil {
+		return err
+	}
+
+	for i := range grants {
+		if grants[i].RoleID == nil {
+			continue
+		}
+
+		if err := loadInheritedPermissions(ctx, db, &grants[i].Role); err != nil {
+			return err
+		}
+	}
+
+	user.Grants = grants
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils"
+)
+
+const (
+	apiKeyPrefix       = "ak_"
+	apiKeyLength       = 40
+	apiKeyVisibleChars = 8
+)
+
+type ApiKeyService interface {
+	GetAllByUserID(ctx context.Context, userID uint) helpers.BaseResponse
+	Create(ctx context.Context, input *model.ApiKeyInput, userID uint) helpers.BaseResponse
+	RevokeByUUID(ctx context.Context, userID uint, uuid uuid.UUID) helpers.BaseResponse
+	Authenticate(ctx context.Context, key string, ip string) (*model.ApiKeyPrincipal, error)
+}
+
+type apiKeyService struct {
+	repository                   repository.ApiKeyRepository
+	userRepository               repository.UserRepository
+	permissionRepository         repository.PermissionRepository
+	permissionImplicationService PermissionImplicationService
+}
+
+func NewApiKeyService(
+	repository repository.ApiKeyRepository, userRepository repository.UserRepository,
+	permissionRepository repository.PermissionRepository, permissionImplicationService PermissionImplicationService,
+) ApiKeyService {
+	return &apiKeyService{
+		repository:                   repository,
+		userRepository:               userRepository,
+		permissionRepository:         permissionRepository,
+		permissionImplicationService: permissionImplicationService,
+	}
+}
+
+func (s *apiKeyService) GetAllByUserID(ctx context.Context, userID uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	apiKeys, err := s.repository.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error retrieving api key data",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Api key data found",
+		Data:    model.ApiKeyToListModels(apiKeys),
+	})
+}
+
+func (s *apiKeyService) Create(ctx context.Context, input *model.ApiKeyInput, userID uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, err := s.userRepository.FindByIDWithRole(ctx, userID)
+	if err != nil || user == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	permissions, err := s.permissionRepository.FindInID(ctx, input.Permissions)
+	if err != nil || len(*permissions) != len(input.Permissions) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Permission data not found",
+			Errors:  err,
+		})
+	}
+
+	// Key could never grant more than the owner currently has
+	if !user.IsAdmin() {
+		granted, err := s.grantedPermissions(ctx, user)
+		if err != nil {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error resolving permissions",
+				Errors:  err,
+			})
+		}
+
+		for _, permission := range *permissions {
+			if _, ok := granted[permission.Name]; !ok {
+				return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+					Status:  fiber.StatusBadRequest,
+					Success: false,
+					Message: "Permission not granted to user",
+					Errors:  fmt.Errorf("permission %s not granted to user", permission.Name),
+				})
+			}
+		}
+	}
+
+	key := apiKeyPrefix + utils.GenerateRandomString(apiKeyLength, true)
+
+	apiKey := input.ToEntity()
+	apiKey.UserID = user.ID
+	apiKey.Prefix = key[:len(apiKeyPrefix)+apiKeyVisibleChars]
+	apiKey.KeyHash = helpers.HashToken(key)
+	apiKey.Permissions = *permissions
+
+	if err := s.repository.Insert(ctx, apiKey); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating data",
+			Errors:  err,
+		})
+	}
+
+	helpers.LogSecurityEvent(ctx, "Api key created", map[string]interface{}{
+		"user_id":    user.ID,
+		"api_key_id": apiKey.UUID,
+		"name":       apiKey.Name,
+	})
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Api key successfully created, store the key now since it will not be shown again",
+		Data: model.ApiKeyCreated{
+			ApiKeyList: *model.ApiKeyToListModel(apiKey),
+			Key:        key,
+		},
+	})
+}
+
+func (s *apiKeyService) RevokeByUUID(ctx context.Context, userID uint, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := s.repository.DeleteByUUID(ctx, userID, uuid); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Api key not found",
+			Errors:  err,
+		})
+	}
+
+	helpers.LogSecurityEvent(ctx, "Api key revoked", map[string]interface{}{
+		"user_id":    userID,
+		"api_key_id": uuid,
+	})
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Api key successfully revoked",
+	})
+}
+
+// Authenticate resolve key into principal, permissions is the key scope narrowed again
+// by owner current permissions so downgraded owner could not keep using broader key, and
+// key of owner that could not sign in (e.g. awaiting approval) is refused
+func (s *apiKeyService) Authenticate(ctx context.Context, key string, ip string) (*model.ApiKeyPrincipal, error) {
+	apiKey, err := s.repository.FindValidByKeyHash(ctx, helpers.HashToken(key))
+	if err != nil {
+		return nil, err
+	}
+
+	// Owner deleted after key created
+	if apiKey.User.ID == 0 {
+		return nil, fmt.Errorf("api key owner not found")
+	}
+
+	if response := accountBlocked(&apiKey.User); response != nil {
+		return nil, fmt.Errorf("api key owner could not sign in: %s", response.Message)
+	}
+
+	var granted map[string]struct{}
+	if !apiKey.User.IsAdmin() {
+		if granted, err = s.grantedPermissions(ctx, &apiKey.User); err != nil {
+			return nil, err
+		}
+	}
+
+	permissions := []string{}
+	for _, permission := range apiKey.Permissions {
+		if granted != nil {
+			if _, ok := granted[permission.Name]; !ok {
+				continue
+			}
+		}
+		permissions = append(permissions, permission.Name)
+	}
+
+	if err := s.repository.TouchLastUsed(ctx, apiKey, ip); err != nil {
+		logData := helpers.CreateLog(s)
+		logData.Message = "Not Passed"
+		logData.Err = err
+		helpers.LogSystemWithDefer(ctx, &logData)
+	}
+
+	return &model.ApiKeyPrincipal{
+		ApiKeyID:          apiKey.ID,
+		UserID:            apiKey.User.ID,
+		Username:          apiKey.User.Username,
+		Email:             apiKey.User.Email,
+		RoleID:            apiKey.User.RoleID,
+		RoleIDs:           apiKey.User.RoleIDs(),
+		Validated:         apiKey.User.ValidatedAt.Valid,
+		ValidatedAt:       apiKey.User.ValidatedAt.Time,
+		Permissions:       permissions,
+		DeniedPermissions: apiKey.User.DeniedPermissionNames(),
+	}, nil
+}
+
+// grantedPermissions return permissions of user expanded by wildcard and implication the same
+// way Authorization match them, without permission denied to user
+func (s *apiKeyService) grantedPermissions(ctx context.Context, user *entity.User) (map[string]struct{}, error) {
+	expanded, err := s.permissionImplicationService.Expand(ctx, user.PermissionNames())
+	if err != nil {
+		return nil, err
+	}
+
+	granted := permissionNameSet(expanded)
+	for _, name := range user.DeniedPermissionNames() {
+		delete(granted, name)
+	}
+
+	return granted, nil
+}
+
+func permissionNameSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+
+	return set
+}
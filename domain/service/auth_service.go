@@ -2,44 +2,97 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
 	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/mail"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
 	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService interface {
 	Login(ctx context.Context, input *model.LoginInput) helpers.BaseResponse
+	Register(ctx context.Context, input *model.RegisterInput) helpers.BaseResponse
 	Logout(ctx context.Context, refreshToken string) helpers.BaseResponse
 	Refresh(ctx context.Context, refreshToken string) helpers.BaseResponse
 	VerifyAccessToken(ctx context.Context, accessToken string) helpers.BaseResponse
 	VerifyRefreshToken(ctx context.Context, refreshToken string) helpers.BaseResponse
+	VerifyEmail(ctx context.Context, input *model.TokenInput) helpers.BaseResponse
+	ResendVerification(ctx context.Context, input *model.EmailInput) helpers.BaseResponse
+	ForgotPassword(ctx context.Context, input *model.EmailInput) helpers.BaseResponse
+	ResetPassword(ctx context.Context, input *model.ResetPasswordInput) helpers.BaseResponse
+	ChangeExpiredPassword(ctx context.Context, input *model.ExpiredPasswordInput) helpers.BaseResponse
 }
 
 type authService struct {
-	refreshTokenRepository repository.RefreshTokenRepository
-	userRepository         repository.UserRepository
+	refreshTokenRepository  repository.RefreshTokenRepository
+	userRepository          repository.UserRepository
+	roleRepository          repository.RoleRepository
+	twoFactorRepository     repository.TwoFactorRepository
+	passwordResetRepository repository.PasswordResetRepository
+	mailClient              *mail.MailClient
+	tokenDenyListService    TokenDenyListService
+	loginThrottleService    LoginThrottleService
+	passwordPolicyService   PasswordPolicyService
 }
 
-func NewAuthService(refreshTokenRepository repository.RefreshTokenRepository, userRepository repository.UserRepository) AuthService {
+func NewAuthService(
+	refreshTokenRepository repository.RefreshTokenRepository, userRepository repository.UserRepository,
+	roleRepository repository.RoleRepository,
+	twoFactorRepository repository.TwoFactorRepository, passwordResetRepository repository.PasswordResetRepository,
+	mailClient *mail.MailClient, tokenDenyListService TokenDenyListService, loginThrottleService LoginThrottleService,
+	passwordPolicyService PasswordPolicyService,
+) AuthService {
 	return &authService{
-		refreshTokenRepository: refreshTokenRepository,
-		userRepository:         userRepository,
+		refreshTokenRepository:  refreshTokenRepository,
+		userRepository:          userRepository,
+		roleRepository:          roleRepository,
+		twoFactorRepository:     twoFactorRepository,
+		passwordResetRepository: passwordResetRepository,
+		mailClient:              mailClient,
+		tokenDenyListService:    tokenDenyListService,
+		loginThrottleService:    loginThrottleService,
+		passwordPolicyService:   passwordPolicyService,
 	}
 }
 
 func (s *authService) Login(ctx context.Context, input *model.LoginInput) helpers.BaseResponse {
-	cfg := config.AppConfig
+	ipAddress, _ := ctx.Value(constant.CtxKeyIPAddress).(string)
 
 	user, err := s.userRepository.FindByUsernameOrEmail(ctx, input.UsernameOrEmail)
+	account := LoginAccount(user, input.UsernameOrEmail)
+
+	// Throttled attempt is rejected before password is checked, so guessing gain nothing
+	if retryAfter, locked := s.loginThrottleService.Check(ctx, account, ipAddress); retryAfter > 0 {
+		status := fiber.StatusTooManyRequests
+		message := "Too many failed login attempts, please try again later"
+		if locked {
+			status = fiber.StatusLocked
+			message = "Account temporarily locked due to too many failed login attempts"
+		}
+
+		return helpers.BaseResponse{
+			Status:  status,
+			Success: false,
+			Message: message,
+			Data:    &model.LoginThrottle{RetryAfter: int(math.Ceil(retryAfter.Seconds()))},
+		}
+	}
+
 	if err != nil {
+		s.loginThrottleService.RecordFailure(ctx, account, ipAddress, 0)
 		return helpers.BaseResponse{
 			Status:  fiber.StatusBadRequest,
 			Success: false,
@@ -48,6 +101,7 @@ func (s *authService) Login(ctx context.Context, input *model.LoginInput) helper
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		s.loginThrottleService.RecordFailure(ctx, account, ipAddress, user.ID)
 		return helpers.BaseResponse{
 			Status:  fiber.StatusBadRequest,
 			Success: false,
@@ -55,52 +109,99 @@ func (s *authService) Login(ctx context.Context, input *model.LoginInput) helper
 		}
 	}
 
-	accessToken, err := helpers.GenerateToken(user, cfg.JwtAccessTime, cfg.JwtAccessPrivateSecret, false)
-	if err != nil {
-		jsonData, _ := json.MarshalIndent(err.Error(), "", " ")
-		log.Println(string(jsonData))
+	// Expired password is only replaced once second factor passed, so password alone could not
+	// take over account protected by second factor
+	return completeLogin(
+		ctx, s.refreshTokenRepository, s.twoFactorRepository, s.loginThrottleService, user, s.passwordPolicyService.IsExpired(user),
+	)
+}
+
+// Register create account for visitor when self registration enabled, the account get the configured
+// default role, need email verification and, when required, admin approval before it could login
+func (s *authService) Register(ctx context.Context, input *model.RegisterInput) helpers.BaseResponse {
+	cfg := config.AppConfig
+	if !cfg.RegistrationEnabled {
 		return helpers.BaseResponse{
-			Status:  fiber.StatusInternalServerError,
+			Status:  fiber.StatusNotFound,
 			Success: false,
-			Errors:  err,
-			Message: "failed generating token",
+			Message: "Registration is not available",
 		}
 	}
 
-	refreshToken, err := helpers.GenerateToken(user, cfg.JwtRefreshTime, cfg.JwtRefreshPrivateSecret, true)
-	if err != nil {
+	// Misconfigured role must never hand out admin to anonymous visitor
+	role, err := s.roleRepository.FindByName(ctx, cfg.RegistrationDefaultRole)
+	if err != nil || role == nil || role.IsAdmin {
+		log.Printf("registration default role %q is missing or admin", cfg.RegistrationDefaultRole)
 		return helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
-			Errors:  err,
-			Message: "failed generating token",
+			Message: "Registration is not available",
 		}
 	}
 
-	if err := s.refreshTokenRepository.Insert(ctx, &entity.RefreshToken{UserID: user.ID, Token: refreshToken}); err != nil {
+	user := input.ToEntity()
+	user.RoleID = role.ID
+	user.Roles = []entity.Role{*role}
+	user.PendingApproval = cfg.RegistrationRequireApproval
+	user.SelfRegistered = true
+
+	errs := s.passwordPolicyService.Validate(ctx, user, input.Password)
+	if s.userRepository.UsernameExist(ctx, user) {
+		errs = append(errs, helpers.ValidationError{
+			Field: "username",
+			Tag:   "duplicate",
+		})
+	}
+	if s.userRepository.EmailExist(ctx, user) {
+		errs = append(errs, helpers.ValidationError{
+			Field: "email",
+			Tag:   "duplicate",
+		})
+	}
+	if len(errs) > 0 {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Errors:  errs,
+			Message: "Invalid or malformed request body",
+		}
+	}
+
+	user.PasswordChangedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.userRepository.Insert(ctx, user); err != nil {
 		return helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
 			Errors:  err,
-			Message: "failed registering token",
+			Message: "Error creating data",
 		}
 	}
 
+	// Password is hashed by the entity hook on insert
+	s.passwordPolicyService.Record(ctx, user.ID, user.Password)
+
+	if err := sendVerificationEmail(ctx, s.mailClient, user); err != nil {
+		log.Printf("failed sending verification email to user %d: %v", user.ID, err)
+	}
+
+	message := "Registration successful, please verify your email"
+	if user.PendingApproval {
+		message = "Registration successful, please verify your email and wait for admin approval"
+		helpers.LogSecurityEvent(ctx, "User registered, awaiting approval", map[string]interface{}{
+			"user_id":  user.ID,
+			"username": user.Username,
+		})
+	}
+
 	return helpers.BaseResponse{
-		Status:  fiber.StatusOK,
+		Status:  fiber.StatusCreated,
 		Success: true,
-		Message: fmt.Sprintf("%s user successfully login", user.Username),
-		Data: &model.AllToken{
-			RefreshToken: refreshToken,
-			AccessToken:  accessToken,
-		},
+		Message: message,
 	}
 }
 
 func (s *authService) Refresh(ctx context.Context, refreshToken string) helpers.BaseResponse {
-	cfg := config.AppConfig
-
-	_, err := helpers.ValidateToken(refreshToken, cfg.JwtRefreshPublicSecret)
+	_, err := helpers.ValidateToken(refreshToken, helpers.RefreshKeyRing())
 	if err != nil {
 		return helpers.BaseResponse{
 			Status:  fiber.StatusForbidden,
@@ -110,7 +211,7 @@ func (s *authService) Refresh(ctx context.Context, refreshToken string) helpers.
 		}
 	}
 
-	tokenEntity, err := s.refreshTokenRepository.FindByToken(ctx, refreshToken)
+	tokenEntity, err := s.refreshTokenRepository.FindByTokenHash(ctx, helpers.HashToken(refreshToken))
 	if err != nil {
 		return helpers.BaseResponse{
 			Status:  fiber.StatusForbidden,
@@ -120,7 +221,12 @@ func (s *authService) Refresh(ctx context.Context, refreshToken string) helpers.
 		}
 	}
 
-	user, err := s.userRepository.FindByID(ctx, tokenEntity.UserID)
+	// Token that already exchanged is presented again, either stolen or replayed
+	if tokenEntity.RotatedAt.Valid {
+		return s.revokeReusedToken(ctx, tokenEntity)
+	}
+
+	user, err := s.userRepository.FindByIDWithRole(ctx, tokenEntity.UserID)
 	if err != nil || user == nil {
 		return helpers.BaseResponse{
 			Status:  fiber.StatusForbidden,
@@ -130,17 +236,28 @@ func (s *authService) Refresh(ctx context.Context, refreshToken string) helpers.
 		}
 	}
 
-	user, err = s.userRepository.FindByUsernameOrEmail(ctx, user.Username)
-	if err != nil || user == nil {
+	// Lost the race against concurrent refresh of the same token (e.g. two tabs or a retry),
+	// token was unused when this request read it so it is not a reuse and family is kept
+	if err := s.refreshTokenRepository.Rotate(ctx, tokenEntity); err != nil {
 		return helpers.BaseResponse{
-			Status:  fiber.StatusForbidden,
+			Status:  fiber.StatusConflict,
 			Success: false,
 			Errors:  err,
-			Message: "User not found",
+			Message: "Token already refreshed by another request",
 		}
 	}
 
-	newAccessToken, err := helpers.GenerateToken(user, cfg.JwtAccessTime, cfg.JwtAccessPrivateSecret, false)
+	// Session moving to other network is not blocked, but recorded for review
+	if ipAddress, _ := ctx.Value(constant.CtxKeyIPAddress).(string); tokenEntity.IPAddress != "" && ipAddress != tokenEntity.IPAddress {
+		helpers.LogSecurityEvent(ctx, "Refresh token used from different IP address", map[string]interface{}{
+			"user_id":     tokenEntity.UserID,
+			"family_id":   tokenEntity.FamilyID,
+			"previous_ip": tokenEntity.IPAddress,
+			"current_ip":  ipAddress,
+		})
+	}
+
+	tokens, err := generateTokenPair(ctx, s.refreshTokenRepository, user, tokenEntity)
 	if err != nil {
 		return helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
@@ -150,49 +267,65 @@ func (s *authService) Refresh(ctx context.Context, refreshToken string) helpers.
 		}
 	}
 
-	newRefreshToken, err := helpers.GenerateToken(user, cfg.JwtRefreshTime, cfg.JwtRefreshPrivateSecret, true)
+	return helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "JWT successfully refresh",
+		Data:    tokens,
+	}
+}
+
+func (s *authService) Logout(ctx context.Context, refreshToken string) helpers.BaseResponse {
+	_, err := helpers.ValidateToken(refreshToken, helpers.RefreshKeyRing())
 	if err != nil {
 		return helpers.BaseResponse{
-			Status:  fiber.StatusInternalServerError,
+			Status:  fiber.StatusForbidden,
 			Success: false,
 			Errors:  err,
-			Message: "failed generating token",
+			Message: "Invalid token",
 		}
 	}
 
-	if err := s.refreshTokenRepository.RevokeByToken(ctx, refreshToken); err != nil {
+	tokenEntity, err := s.refreshTokenRepository.FindByTokenHash(ctx, helpers.HashToken(refreshToken))
+	if err != nil {
 		return helpers.BaseResponse{
-			Status:  fiber.StatusInternalServerError,
+			Status:  fiber.StatusForbidden,
 			Success: false,
 			Errors:  err,
-			Message: "failed revoking token",
+			Message: "Invalid token",
 		}
 	}
 
-	if err := s.refreshTokenRepository.Insert(ctx, &entity.RefreshToken{UserID: user.ID, Token: newRefreshToken}); err != nil {
+	user, err := s.userRepository.FindByID(ctx, tokenEntity.UserID)
+	if err != nil || user == nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Errors:  err,
+			Message: "Invalid token",
+		}
+	}
+
+	// Logout end the device chain, so rotated ancestor could not be replayed either
+	s.tokenDenyListService.DenySession(ctx, tokenEntity.UserID, tokenEntity.SessionID())
+	if err := s.refreshTokenRepository.RevokeFamily(ctx, tokenEntity); err != nil {
 		return helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
 			Errors:  err,
-			Message: "failed registering token",
+			Message: "failed revoking token",
 		}
 	}
 
 	return helpers.BaseResponse{
 		Status:  fiber.StatusOK,
 		Success: true,
-		Message: "JWT successfully refresh",
-		Data: &model.AllToken{
-			RefreshToken: newRefreshToken,
-			AccessToken:  newAccessToken,
-		},
+		Message: fmt.Sprintf("%s user successfully logout", user.Username),
 	}
 }
 
-func (s *authService) Logout(ctx context.Context, refreshToken string) helpers.BaseResponse {
-	cfg := config.AppConfig
-
-	_, err := helpers.ValidateToken(refreshToken, cfg.JwtRefreshPublicSecret)
+func (s *authService) VerifyAccessToken(ctx context.Context, accessToken string) helpers.BaseResponse {
+	_, err := helpers.ValidateToken(accessToken, helpers.AccessKeyRing())
 	if err != nil {
 		return helpers.BaseResponse{
 			Status:  fiber.StatusForbidden,
@@ -202,7 +335,15 @@ func (s *authService) Logout(ctx context.Context, refreshToken string) helpers.B
 		}
 	}
 
-	tokenEntity, err := s.refreshTokenRepository.FindByToken(ctx, refreshToken)
+	return helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Access token valid",
+	}
+}
+
+func (s *authService) VerifyRefreshToken(ctx context.Context, refreshToken string) helpers.BaseResponse {
+	_, err := helpers.ValidateToken(refreshToken, helpers.RefreshKeyRing())
 	if err != nil {
 		return helpers.BaseResponse{
 			Status:  fiber.StatusForbidden,
@@ -212,6 +353,16 @@ func (s *authService) Logout(ctx context.Context, refreshToken string) helpers.B
 		}
 	}
 
+	tokenEntity, err := s.refreshTokenRepository.FindByTokenHash(ctx, helpers.HashToken(refreshToken))
+	if err != nil || tokenEntity.RotatedAt.Valid {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Errors:  err,
+			Message: "Invalid token",
+		}
+	}
+
 	user, err := s.userRepository.FindByID(ctx, tokenEntity.UserID)
 	if err != nil || user == nil {
 		return helpers.BaseResponse{
@@ -222,78 +373,541 @@ func (s *authService) Logout(ctx context.Context, refreshToken string) helpers.B
 		}
 	}
 
-	if err := s.refreshTokenRepository.RevokeByToken(ctx, refreshToken); err != nil {
+	return helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Refresh token valid",
+	}
+}
+
+func (s *authService) VerifyEmail(ctx context.Context, input *model.TokenInput) helpers.BaseResponse {
+	claim, err := helpers.ValidateToken(input.Token, helpers.ChallengeKeyRing())
+	if err != nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Errors:  err,
+			Message: "Invalid or expired verification token",
+		}
+	}
+
+	userID, ok := claim["sub"].(float64)
+	if purpose, _ := claim["purpose"].(string); !ok || purpose != constant.TokenPurposeEmailVerify {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or expired verification token",
+		}
+	}
+
+	user, err := s.userRepository.FindByID(ctx, uint(userID))
+	if err != nil || user == nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Errors:  err,
+			Message: "Invalid or expired verification token",
+		}
+	}
+
+	// Link sent to previous email must not verify email changed after it
+	if emailHash, _ := claim["email_hash"].(string); emailHash != helpers.HashToken(user.Email) {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or expired verification token",
+		}
+	}
+
+	if user.ValidatedAt.Valid {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusOK,
+			Success: true,
+			Message: "Email already verified",
+		}
+	}
+
+	if err := s.userRepository.Update(ctx, &entity.User{
+		ID:          user.ID,
+		ValidatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}); err != nil {
 		return helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
 			Errors:  err,
-			Message: "failed revoking token",
+			Message: "Error verifying email",
 		}
 	}
 
 	return helpers.BaseResponse{
 		Status:  fiber.StatusOK,
 		Success: true,
-		Message: fmt.Sprintf("%s user successfully logout", user.Username),
+		Message: "Email successfully verified",
 	}
 }
 
-func (s *authService) VerifyAccessToken(ctx context.Context, accessToken string) helpers.BaseResponse {
+func (s *authService) ResendVerification(ctx context.Context, input *model.EmailInput) helpers.BaseResponse {
+	// Same response is returned whether the email registered or not, to avoid account enumeration
+	response := helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "If the email is registered and not verified yet, a verification email has been sent",
+	}
+
+	user, err := s.userRepository.FindByUsernameOrEmail(ctx, input.Email)
+	if err != nil || user == nil || user.Email != input.Email || user.ValidatedAt.Valid {
+		return response
+	}
+
+	// Mail is sent in background so response time does not reveal whether the account exist
+	go func() {
+		if err := sendVerificationEmail(context.Background(), s.mailClient, user); err != nil {
+			log.Printf("failed sending verification email to user %d: %v", user.ID, err)
+		}
+	}()
+
+	return response
+}
+
+func (s *authService) ForgotPassword(ctx context.Context, input *model.EmailInput) helpers.BaseResponse {
 	cfg := config.AppConfig
 
-	_, err := helpers.ValidateToken(accessToken, cfg.JwtAccessPublicSecret)
-	if err != nil {
+	// Same response is returned whether the email registered or not, to avoid account enumeration
+	response := helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "If the email is registered, a password reset link has been sent",
+	}
+
+	user, err := s.userRepository.FindByUsernameOrEmail(ctx, input.Email)
+	if err != nil || user == nil || user.Email != input.Email {
+		return response
+	}
+
+	// Only the latest requested link stay usable
+	if err := s.passwordResetRepository.DeleteUnusedByUserID(ctx, user.ID); err != nil {
+		log.Printf("failed clearing password reset of user %d: %v", user.ID, err)
+	}
+
+	token := utils.GenerateRandomString(64, true)
+	if err := s.passwordResetRepository.Insert(ctx, &entity.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: helpers.HashToken(token),
+		ExpiredAt: time.Now().Add(time.Duration(cfg.PasswordResetTime) * time.Minute),
+	}); err != nil {
+		log.Printf("failed registering password reset of user %d: %v", user.ID, err)
+		return response
+	}
+
+	// Mail is sent in background so response time does not reveal whether the account exist
+	go func() {
+		if err := sendPasswordResetEmail(context.Background(), s.mailClient, user, token); err != nil {
+			log.Printf("failed sending password reset email to user %d: %v", user.ID, err)
+		}
+	}()
+
+	return response
+}
+
+func (s *authService) ResetPassword(ctx context.Context, input *model.ResetPasswordInput) helpers.BaseResponse {
+	passwordReset, err := s.passwordResetRepository.FindValidByTokenHash(ctx, helpers.HashToken(input.Token))
+	if err != nil || passwordReset == nil {
 		return helpers.BaseResponse{
-			Status:  fiber.StatusForbidden,
+			Status:  fiber.StatusBadRequest,
 			Success: false,
 			Errors:  err,
-			Message: "Invalid token",
+			Message: "Invalid or expired reset token",
+		}
+	}
+
+	user, err := s.userRepository.FindByID(ctx, passwordReset.UserID)
+	if err != nil || user == nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Errors:  err,
+			Message: "Invalid or expired reset token",
+		}
+	}
+
+	// Checked before the token is consumed so user could retry with other password
+	if errs := s.passwordPolicyService.Validate(ctx, user, input.Password); len(errs) > 0 {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Errors:  errs,
+			Message: "Invalid or malformed request body",
+		}
+	}
+
+	if err := s.passwordResetRepository.MarkUsed(ctx, passwordReset); err != nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Errors:  err,
+			Message: "Invalid or expired reset token",
+		}
+	}
+
+	if err := s.updatePassword(ctx, user.ID, input.Password); err != nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Errors:  err,
+			Message: "Error resetting password",
+		}
+	}
+
+	// Owner proved control of the email, lockout caused by guessing old password is lifted
+	s.loginThrottleService.Unlock(ctx, user.ID)
+
+	// Sign out every device, the old password might be compromised
+	s.tokenDenyListService.DenyUser(ctx, user.ID)
+	if err := s.refreshTokenRepository.RevokeAllByUserID(ctx, user.ID); err != nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Errors:  err,
+			Message: "failed revoking token",
 		}
 	}
 
 	return helpers.BaseResponse{
 		Status:  fiber.StatusOK,
 		Success: true,
-		Message: "Access token valid",
+		Message: "Password successfully reset, please login again",
 	}
 }
 
-func (s *authService) VerifyRefreshToken(ctx context.Context, refreshToken string) helpers.BaseResponse {
-	cfg := config.AppConfig
+// ChangeExpiredPassword replace expired password of user holding the challenge token issued by login
+// after second factor passed, then sign out other device and issue token pair
+func (s *authService) ChangeExpiredPassword(ctx context.Context, input *model.ExpiredPasswordInput) helpers.BaseResponse {
+	claim, err := helpers.ValidateToken(input.ChallengeToken, helpers.ChallengeKeyRing())
+	if err != nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusUnauthorized,
+			Success: false,
+			Errors:  err,
+			Message: "Invalid or expired challenge token",
+		}
+	}
 
-	_, err := helpers.ValidateToken(refreshToken, cfg.JwtRefreshPublicSecret)
+	userID, ok := claim["sub"].(float64)
+	if purpose, _ := claim["purpose"].(string); !ok || purpose != constant.TokenPurposePasswordChange {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusUnauthorized,
+			Success: false,
+			Message: "Invalid or expired challenge token",
+		}
+	}
+
+	user, err := s.userRepository.FindByIDWithRole(ctx, uint(userID))
+	if err != nil || user == nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusUnauthorized,
+			Success: false,
+			Errors:  err,
+			Message: "Invalid or expired challenge token",
+		}
+	}
+
+	// Challenge token is bound to the password it was issued for, so it is single use
+	if passwordHash, _ := claim["password_hash"].(string); passwordHash != helpers.HashToken(user.Password) {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusUnauthorized,
+			Success: false,
+			Message: "Invalid or expired challenge token",
+		}
+	}
+
+	if errs := s.passwordPolicyService.Validate(ctx, user, input.Password); len(errs) > 0 {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Errors:  errs,
+			Message: "Invalid or malformed request body",
+		}
+	}
+
+	if err := s.updatePassword(ctx, user.ID, input.Password); err != nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Errors:  err,
+			Message: "Error changing password",
+		}
+	}
+
+	helpers.LogSecurityEvent(ctx, "Expired password changed", map[string]interface{}{
+		"user_id": user.ID,
+	})
+
+	// Sign out every device, the old password might be compromised
+	s.tokenDenyListService.DenyUser(ctx, user.ID)
+	if err := s.refreshTokenRepository.RevokeAllByUserID(ctx, user.ID); err != nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Errors:  err,
+			Message: "failed revoking token",
+		}
+	}
+
+	// Second factor was already passed before the challenge token was issued
+	tokens, err := generateTokenPair(ctx, s.refreshTokenRepository, user, nil)
 	if err != nil {
 		return helpers.BaseResponse{
-			Status:  fiber.StatusForbidden,
+			Status:  fiber.StatusInternalServerError,
 			Success: false,
 			Errors:  err,
-			Message: "Invalid token",
+			Message: "failed generating token",
 		}
 	}
 
-	tokenEntity, err := s.refreshTokenRepository.FindByToken(ctx, refreshToken)
+	s.loginThrottleService.Reset(ctx, LoginAccount(user, ""))
+
+	return helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: fmt.Sprintf("%s user successfully login", user.Username),
+		Data:    tokens,
+	}
+}
+
+// updatePassword hash and store new password of user, then remember it in password history
+func (s *authService) updatePassword(ctx context.Context, userID uint, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepository.Update(ctx, &entity.User{
+		ID:                userID,
+		Password:          string(hashedPassword),
+		PasswordChangedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}); err != nil {
+		return err
+	}
+
+	s.passwordPolicyService.Record(ctx, userID, string(hashedPassword))
+
+	return nil
+}
+
+// passwordChangeChallenge ask user with expired password to change it, tokens are only issued
+// once the password is changed through the challenge token
+func passwordChangeChallenge(user *entity.User, recoveryCodes []string) helpers.BaseResponse {
+	cfg := config.AppConfig
+
+	challengeToken, err := helpers.GenerateChallengeTokenWithClaims(
+		user.ID, constant.TokenPurposePasswordChange, map[string]interface{}{"password_hash": helpers.HashToken(user.Password)},
+		cfg.PasswordChangeTime, helpers.ChallengeKeyRing(),
+	)
 	if err != nil {
 		return helpers.BaseResponse{
-			Status:  fiber.StatusForbidden,
+			Status:  fiber.StatusInternalServerError,
 			Success: false,
 			Errors:  err,
-			Message: "Invalid token",
+			Message: "failed generating token",
 		}
 	}
 
-	user, err := s.userRepository.FindByID(ctx, tokenEntity.UserID)
-	if err != nil || user == nil {
+	return helpers.BaseResponse{
+		Status:  fiber.StatusAccepted,
+		Success: true,
+		Message: "Password expired, please change password",
+		Data: &model.PasswordChangeChallenge{
+			ChallengeToken: challengeToken,
+			ExpiredAt:      time.Now().Add(time.Duration(cfg.PasswordChangeTime) * time.Minute),
+			RecoveryCodes:  recoveryCodes,
+		},
+	}
+}
+
+// completeLogin finish login of authenticated user, user still need to pass second factor
+// either because user enabled it or the role enforce it, then change expired password,
+// otherwise token pair is issued and failed login counter is cleared. Counter is kept while
+// challenge is pending, so correct password could not clear failures of second factor guessing
+func completeLogin(
+	ctx context.Context, refreshTokenRepository repository.RefreshTokenRepository,
+	twoFactorRepository repository.TwoFactorRepository, loginThrottleService LoginThrottleService,
+	user *entity.User, passwordExpired bool,
+) helpers.BaseResponse {
+	cfg := config.AppConfig
+
+	if response := accountBlocked(user); response != nil {
+		return *response
+	}
+
+	twoFactor, _ := twoFactorRepository.FindByUserID(ctx, user.ID)
+	if twoFactor.IsEnabled() || user.RequireTwoFactor() {
+		purpose := constant.TokenPurposeTwoFactorVerify
+		if !twoFactor.IsEnabled() {
+			purpose = constant.TokenPurposeTwoFactorEnroll
+		}
+
+		// Verification continue with password change instead of issuing token pair
+		var extra map[string]interface{}
+		if passwordExpired {
+			extra = map[string]interface{}{"password_expired": true}
+		}
+
+		challengeToken, err := helpers.GenerateChallengeTokenWithClaims(user.ID, purpose, extra, cfg.TwoFactorChallengeTime, helpers.ChallengeKeyRing())
+		if err != nil {
+			return helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Errors:  err,
+				Message: "failed generating token",
+			}
+		}
+
 		return helpers.BaseResponse{
-			Status:  fiber.StatusForbidden,
+			Status:  fiber.StatusAccepted,
+			Success: true,
+			Message: "Two-factor authentication required",
+			Data: &model.TwoFactorChallenge{
+				ChallengeToken:     challengeToken,
+				ExpiredAt:          time.Now().Add(time.Duration(cfg.TwoFactorChallengeTime) * time.Minute),
+				EnrollmentRequired: !twoFactor.IsEnabled(),
+			},
+		}
+	}
+
+	if passwordExpired {
+		return passwordChangeChallenge(user, nil)
+	}
+
+	tokens, err := generateTokenPair(ctx, refreshTokenRepository, user, nil)
+	if err != nil {
+		jsonData, _ := json.MarshalIndent(err.Error(), "", " ")
+		log.Println(string(jsonData))
+		return helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
 			Success: false,
 			Errors:  err,
-			Message: "Invalid token",
+			Message: "failed generating token",
 		}
 	}
 
+	loginThrottleService.Reset(ctx, LoginAccount(user, ""))
+
 	return helpers.BaseResponse{
 		Status:  fiber.StatusOK,
 		Success: true,
-		Message: "Refresh token valid",
+		Message: fmt.Sprintf("%s user successfully login", user.Username),
+		Data:    tokens,
 	}
 }
+
+// accountBlocked return response refusing user whose account could not sign in yet, nil when the
+// account is usable. Checked by every way of signing in, including api key of the user
+func accountBlocked(user *entity.User) *helpers.BaseResponse {
+	// Self registered user could not login until admin approve the account
+	if user.PendingApproval {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Account is awaiting admin approval",
+		}
+	}
+
+	// Self registered account is only usable once the visitor proved owning the email
+	if user.SelfRegistered && !user.ValidatedAt.Valid {
+		return &helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Email is not verified, please verify your email before login",
+		}
+	}
+
+	return nil
+}
+
+// revokeReusedToken handle replay of rotated refresh token by revoking the whole family,
+// forcing every device in the chain (including attacker) to login again
+func (s *authService) revokeReusedToken(ctx context.Context, tokenEntity *entity.RefreshToken) helpers.BaseResponse {
+	helpers.LogSecurityEvent(ctx, "Refresh token reuse detected, token family revoked", map[string]interface{}{
+		"user_id":   tokenEntity.UserID,
+		"family_id": tokenEntity.FamilyID,
+		"token_id":  tokenEntity.UUID,
+	})
+
+	s.tokenDenyListService.DenySession(ctx, tokenEntity.UserID, tokenEntity.SessionID())
+	if err := s.refreshTokenRepository.RevokeFamily(ctx, tokenEntity); err != nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Errors:  err,
+			Message: "failed revoking token",
+		}
+	}
+
+	return helpers.BaseResponse{
+		Status:  fiber.StatusUnauthorized,
+		Success: false,
+		Message: "Refresh token reuse detected, please login again",
+	}
+}
+
+// generateTokenPair issue new access and refresh token for user, then register the refresh token
+// so it could be rotated or revoked later. When parent given, new refresh token join the parent family
+func generateTokenPair(
+	ctx context.Context, refreshTokenRepository repository.RefreshTokenRepository, user *entity.User, parent *entity.RefreshToken,
+) (*model.AllToken, error) {
+	cfg := config.AppConfig
+
+	accessJTI := uuid.New().String()
+	accessToken, err := helpers.GenerateToken(user, accessJTI, cfg.JwtAccessTime, helpers.AccessKeyRing(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := helpers.GenerateToken(user, uuid.New().String(), cfg.JwtRefreshTime, helpers.RefreshKeyRing(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Access token is not stored, only its id so it could be put on deny list before expired
+	tokenEntity := &entity.RefreshToken{
+		UserID:          user.ID,
+		TokenHash:       helpers.HashToken(refreshToken),
+		AccessJTI:       accessJTI,
+		AccessExpiredAt: time.Now().Add(time.Duration(cfg.JwtAccessTime) * time.Minute),
+		LastUsedAt:      sql.NullTime{Time: time.Now(), Valid: true},
+	}
+
+	// Rotated token keep the client metadata of the login that started the family,
+	// legacy token without metadata take it from current request instead
+	if parent != nil {
+		tokenEntity.FamilyID = parent.FamilyID
+		tokenEntity.ParentID = &parent.UUID
+		tokenEntity.IPAddress = parent.IPAddress
+		tokenEntity.UserAgent = parent.UserAgent
+		tokenEntity.Device = parent.Device
+	}
+
+	if tokenEntity.IPAddress == "" {
+		tokenEntity.IPAddress, _ = ctx.Value(constant.CtxKeyIPAddress).(string)
+	}
+	if tokenEntity.UserAgent == "" {
+		userAgent, _ := ctx.Value(constant.CtxKeyUserAgent).(string)
+		// Keep within column size
+		if len(userAgent) > 255 {
+			userAgent = userAgent[:255]
+		}
+		tokenEntity.UserAgent = userAgent
+	}
+	if tokenEntity.Device == "" {
+		tokenEntity.Device = helpers.ParseDeviceLabel(tokenEntity.UserAgent)
+	}
+
+	if err := refreshTokenRepository.Insert(ctx, tokenEntity); err != nil {
+		return nil, fmt.Errorf("failed registering token: %w", err)
+	}
+
+	return &model.AllToken{
+		RefreshToken: refreshToken,
+		AccessToken:  accessToken,
+	}, nil
+}
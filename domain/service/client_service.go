@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils"
+)
+
+const (
+	clientIDPrefix     = "cl_"
+	clientIDLength     = 24
+	clientSecretPrefix = "cs_"
+	clientSecretLength = 48
+
+	grantTypeClientCredentials = "client_credentials"
+)
+
+type ClientService interface {
+	GetAll(ctx context.Context) helpers.BaseResponse
+	GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	Create(ctx context.Context, input *model.ClientInput) helpers.BaseResponse
+	UpdateByUUID(ctx context.Context, input *model.ClientInput, uuid uuid.UUID) helpers.BaseResponse
+	RotateSecretByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse
+	IssueToken(ctx context.Context, input *model.ClientCredentialsInput) helpers.BaseResponse
+}
+
+type clientService struct {
+	repository           repository.ClientRepository
+	permissionRepository repository.PermissionRepository
+	tokenVersionService  TokenVersionService
+}
+
+func NewClientService(
+	repository repository.ClientRepository, permissionRepository repository.PermissionRepository,
+	tokenVersionService TokenVersionService,
+) ClientService {
+	return &clientService{
+		repository:           repository,
+		permissionRepository: permissionRepository,
+		tokenVersionService:  tokenVersionService,
+	}
+}
+
+func (s *clientService) GetAll(ctx context.Context) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	clients, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error retrieving client data",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Client data found",
+		Data:    model.ClientToListModels(clients),
+	})
+}
+
+func (s *clientService) GetByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	client, err := s.repository.FindByUUID(ctx, uuid)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Client not found",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Client data found",
+		Data:    model.ClientToListModel(client),
+	})
+}
+
+func (s *clientService) Create(ctx context.Context, input *model.ClientInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	permissions, err := s.permissionRepository.FindInID(ctx, input.Scopes)
+	if err != nil || len(*permissions) != len(input.Scopes) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Permission data not found",
+			Errors:  err,
+		})
+	}
+
+	secret := clientSecretPrefix + utils.GenerateRandomString(clientSecretLength, true)
+
+	client := input.ToEntity()
+	client.ClientID = clientIDPrefix + utils.GenerateRandomString(clientIDLength, true)
+	client.SecretHash = helpers.HashToken(secret)
+	client.Scopes = *permissions
+
+	if err := s.repository.Insert(ctx, client); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating data",
+			Errors:  err,
+		})
+	}
+
+	helpers.LogSecurityEvent(ctx, "OAuth client created", map[string]interface{}{
+		"client_id": client.ClientID,
+		"name":      client.Name,
+	})
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Client successfully created, store the secret now since it will not be shown again",
+		Data: model.ClientCreated{
+			ClientList:   *model.ClientToListModel(client),
+			ClientSecret: secret,
+		},
+	})
+}
+
+func (s *clientService) UpdateByUUID(ctx context.Context, input *model.ClientInput, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	client, err := s.repository.FindByUUID(ctx, uuid)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Client not found",
+			Errors:  err,
+		})
+	}
+
+	permissions, err := s.permissionRepository.FindInID(ctx, input.Scopes)
+	if err != nil || len(*permissions) != len(input.Scopes) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Permission data not found",
+			Errors:  err,
+		})
+	}
+
+	client.Name = input.Name
+	if err := s.repository.Update(ctx, client, permissions); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+			Errors:  err,
+		})
+	}
+
+	// Token issued with previous scopes stop working right away
+	s.tokenVersionService.BumpClient(ctx, client.ClientID)
+
+	helpers.LogSecurityEvent(ctx, "OAuth client updated", map[string]interface{}{
+		"client_id": client.ClientID,
+		"name":      client.Name,
+	})
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Client successfully updated",
+	})
+}
+
+func (s *clientService) RotateSecretByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	client, err := s.repository.FindByUUID(ctx, uuid)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Client not found",
+			Errors:  err,
+		})
+	}
+
+	secret := clientSecretPrefix + utils.GenerateRandomString(clientSecretLength, true)
+	client.SecretHash = helpers.HashToken(secret)
+
+	if err := s.repository.UpdateSecret(ctx, client); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+			Errors:  err,
+		})
+	}
+
+	// Secret may have leaked, token obtained with the old one is revoked too
+	s.tokenVersionService.BumpClient(ctx, client.ClientID)
+
+	helpers.LogSecurityEvent(ctx, "OAuth client secret rotated", map[string]interface{}{
+		"client_id": client.ClientID,
+	})
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Client secret successfully rotated, store the secret now since it will not be shown again",
+		Data: model.ClientCreated{
+			ClientList:   *model.ClientToListModel(client),
+			ClientSecret: secret,
+		},
+	})
+}
+
+func (s *clientService) DeleteByUUID(ctx context.Context, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	client, err := s.repository.FindByUUID(ctx, uuid)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Client not found",
+			Errors:  err,
+		})
+	}
+
+	// Bump while the row still exist so cached version is replaced before deletion
+	s.tokenVersionService.BumpClient(ctx, client.ClientID)
+
+	if err := s.repository.Delete(ctx, client); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error deleting data",
+			Errors:  err,
+		})
+	}
+
+	helpers.LogSecurityEvent(ctx, "OAuth client deleted", map[string]interface{}{
+		"client_id": client.ClientID,
+	})
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Client successfully deleted",
+	})
+}
+
+// IssueToken implement client credentials grant, error response carry model.OAuthError
+// so handler could answer in RFC 6749 format
+func (s *clientService) IssueToken(ctx context.Context, input *model.ClientCredentialsInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if input.GrantType != grantTypeClientCredentials {
+		return helpers.LogBaseResponse(&logData, oauthErrorResponse(
+			fiber.StatusBadRequest, "unsupported_grant_type", "Only client_credentials grant is supported",
+		))
+	}
+
+	client, err := s.repository.FindByClientID(ctx, input.ClientID)
+	if err != nil || subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(helpers.HashToken(input.ClientSecret))) != 1 {
+		helpers.LogSecurityEvent(ctx, "OAuth client authentication failed", map[string]interface{}{
+			"client_id": input.ClientID,
+		})
+
+		return helpers.LogBaseResponse(&logData, oauthErrorResponse(
+			fiber.StatusUnauthorized, "invalid_client", "Client authentication failed",
+		))
+	}
+
+	// Requested scope narrow what the client is allowed, empty scope grant everything allowed
+	allowed := make(map[string]string, len(client.Scopes))
+	for _, permission := range client.Scopes {
+		allowed[utils.PermissionToScope(permission.Name)] = permission.Name
+	}
+
+	permissions := []string{}
+	if requested := strings.Fields(input.Scope); len(requested) > 0 {
+		for _, scope := range requested {
+			permission, ok := allowed[scope]
+			if !ok {
+				return helpers.LogBaseResponse(&logData, oauthErrorResponse(
+					fiber.StatusBadRequest, "invalid_scope", fmt.Sprintf("Scope %s is not allowed for this client", scope),
+				))
+			}
+			permissions = append(permissions, permission)
+		}
+	} else {
+		for _, permission := range client.Scopes {
+			permissions = append(permissions, permission.Name)
+		}
+	}
+
+	cfg := config.AppConfig
+	accessToken, err := helpers.GenerateClientToken(
+		client, permissions, uuid.New().String(), cfg.OAuthClientTokenTime, helpers.AccessKeyRing(),
+	)
+	if err != nil {
+		response := helpers.LogBaseResponse(&logData, oauthErrorResponse(
+			fiber.StatusInternalServerError, "server_error", "Error generating token",
+		))
+		logData.Err = err
+		return response
+	}
+
+	scopes := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		scopes = append(scopes, utils.PermissionToScope(permission))
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Token issued",
+		Data: model.OAuthToken{
+			AccessToken: accessToken,
+			TokenType:   "Bearer",
+			ExpiresIn:   cfg.OAuthClientTokenTime * 60,
+			Scope:       strings.Join(scopes, " "),
+		},
+	})
+}
+
+func oauthErrorResponse(status int, code string, description string) helpers.BaseResponse {
+	return helpers.BaseResponse{
+		Status:  status,
+		Success: false,
+		Message: description,
+		Errors: model.OAuthError{
+			Error:            code,
+			ErrorDescription: description,
+		},
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+)
+
+// ImpersonationService let admin act as other user to reproduce their issue, every start and
+// end is written as security event, and request made meanwhile carry the admin in log
+type ImpersonationService interface {
+	Start(ctx context.Context, userID uint) helpers.BaseResponse
+	End(ctx context.Context, jti string, expiredAt time.Time) helpers.BaseResponse
+}
+
+type impersonationService struct {
+	userRepository       repository.UserRepository
+	tokenDenyListService TokenDenyListService
+}
+
+func NewImpersonationService(
+	userRepository repository.UserRepository, tokenDenyListService TokenDenyListService,
+) ImpersonationService {
+	return &impersonationService{
+		userRepository:       userRepository,
+		tokenDenyListService: tokenDenyListService,
+	}
+}
+
+func (s *impersonationService) Start(ctx context.Context, userID uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	cfg := config.AppConfig
+
+	adminID, _ := ctx.Value(constant.CtxKeyUserID).(float64)
+	admin, err := s.userRepository.FindByIDWithRole(ctx, uint(adminID))
+	if err != nil || admin == nil || !admin.IsAdmin() {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Unauthorized to access this resource",
+			Errors:  err,
+		})
+	}
+
+	user, err := s.userRepository.FindByIDWithRole(ctx, userID)
+	if err != nil || user == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	// Acting as other admin would let one admin use privilege on behalf of another
+	if user.ID == admin.ID || user.IsAdmin() {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Admin user could not be impersonated",
+		})
+	}
+
+	jti := uuid.New().String()
+	accessToken, err := helpers.GenerateImpersonationToken(user, admin, jti, cfg.ImpersonationTime, helpers.AccessKeyRing())
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed generating token",
+			Errors:  err,
+		})
+	}
+
+	expiredAt := time.Now().Add(time.Duration(cfg.ImpersonationTime) * time.Minute)
+	helpers.LogSecurityEvent(ctx, "Impersonation started", map[string]interface{}{
+		"admin_id":   admin.ID,
+		"admin":      admin.Username,
+		"user_id":    user.ID,
+		"username":   user.Username,
+		"jti":        jti,
+		"expired_at": expiredAt,
+	})
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Impersonation started",
+		Data: &model.ImpersonationToken{
+			AccessToken: accessToken,
+			ExpiredAt:   expiredAt,
+			UserID:      user.ID,
+			Username:    user.Username,
+		},
+	})
+}
+
+// End revoke the impersonation token used by the current request
+func (s *impersonationService) End(ctx context.Context, jti string, expiredAt time.Time) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	s.tokenDenyListService.DenyToken(ctx, jti, expiredAt)
+
+	userID, _ := ctx.Value(constant.CtxKeyUserID).(float64)
+	impersonator, _ := ctx.Value(constant.CtxKeyImpersonator).(string)
+	helpers.LogSecurityEvent(ctx, "Impersonation ended", map[string]interface{}{
+		"admin":   impersonator,
+		"user_id": uint(userID),
+		"jti":     jti,
+	})
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Impersonation ended",
+	})
+}
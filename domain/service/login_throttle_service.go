@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+// Failed attempt allowed back to back before delay start growing
+const loginFreeAttempt = 2
+
+// LoginThrottleService count failed login per account and per IP address in redis. Each failure
+// past the free attempt double the wait before next attempt, account is locked once it reach
+// LOGIN_MAX_ATTEMPT and IP address is blocked once it reach LOGIN_MAX_ATTEMPT_IP
+type LoginThrottleService interface {
+	Check(ctx context.Context, account string, ip string) (retryAfter time.Duration, locked bool)
+	RecordFailure(ctx context.Context, account string, ip string, userID uint)
+	Reset(ctx context.Context, account string)
+	Unlock(ctx context.Context, userID uint)
+}
+
+type loginThrottleService struct {
+	cacheRedis *redis.CacheClient
+}
+
+func NewLoginThrottleService(cacheRedis *redis.CacheClient) LoginThrottleService {
+	return &loginThrottleService{
+		cacheRedis: cacheRedis,
+	}
+}
+
+// LoginAccount return throttle key of login identifier, existing user is keyed by id so username
+// and email share one counter, unknown identifier is counted too so lockout does not reveal
+// whether account exist
+func LoginAccount(user *entity.User, identifier string) string {
+	if user != nil {
+		return fmt.Sprintf("user-id:%d", user.ID)
+	}
+
+	return "identifier:" + helpers.HashToken(strings.ToLower(strings.TrimSpace(identifier)))
+}
+
+func (s *loginThrottleService) Check(ctx context.Context, account string, ip string) (time.Duration, bool) {
+	if ttl, err := s.cacheRedis.TTL(ctx, loginLockKey(account)); err == nil && ttl > 0 {
+		return ttl, true
+	}
+
+	cfg := config.AppConfig
+	if data, err := s.cacheRedis.Get(ctx, loginIPAttemptKey(ip), nil); err == nil {
+		if failures, err := strconv.Atoi(data); err == nil && failures >= cfg.LoginMaxAttemptIP {
+			ttl, _ := s.cacheRedis.TTL(ctx, loginIPAttemptKey(ip))
+			return ttl, false
+		}
+	}
+
+	if ttl, err := s.cacheRedis.TTL(ctx, loginDelayKey(account)); err == nil && ttl > 0 {
+		return ttl, false
+	}
+
+	return 0, false
+}
+
+func (s *loginThrottleService) RecordFailure(ctx context.Context, account string, ip string, userID uint) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	cfg := config.AppConfig
+	window := time.Duration(cfg.LoginAttemptWindow) * time.Minute
+
+	ipFailures, err := s.cacheRedis.Incr(ctx, loginIPAttemptKey(ip), window)
+	if err != nil {
+		// Redis unavailable should not stop login, password is still checked
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return
+	}
+
+	if ipFailures == int64(cfg.LoginMaxAttemptIP) {
+		helpers.LogSecurityEvent(ctx, "IP address blocked after repeated failed login", map[string]interface{}{
+			"ip_address": ip,
+			"failures":   ipFailures,
+		})
+	}
+
+	failures, err := s.cacheRedis.Incr(ctx, loginAttemptKey(account), window)
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return
+	}
+
+	if failures >= int64(cfg.LoginMaxAttempt) {
+		lockout := time.Duration(cfg.LoginLockoutTime) * time.Minute
+		if err := s.cacheRedis.Set(ctx, loginLockKey(account), failures, lockout); err != nil {
+			logData.Message = "Not Passed"
+			logData.Err = err
+			return
+		}
+
+		// Counting start over once lock expired
+		s.cacheRedis.Del(ctx, loginAttemptKey(account), loginDelayKey(account))
+
+		helpers.LogSecurityEvent(ctx, "Account locked after repeated failed login", map[string]interface{}{
+			"account":      account,
+			"user_id":      userID,
+			"ip_address":   ip,
+			"failures":     failures,
+			"locked_until": time.Now().Add(lockout),
+		})
+		return
+	}
+
+	if failures > loginFreeAttempt {
+		delay := math.Min(math.Pow(2, float64(failures-loginFreeAttempt-1)), float64(cfg.LoginDelayMax))
+		if err := s.cacheRedis.Set(ctx, loginDelayKey(account), failures, time.Duration(delay)*time.Second); err != nil {
+			logData.Message = "Not Passed"
+			logData.Err = err
+		}
+	}
+}
+
+func (s *loginThrottleService) Reset(ctx context.Context, account string) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := s.cacheRedis.Del(ctx, loginAttemptKey(account), loginDelayKey(account), loginLockKey(account)); err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+}
+
+func (s *loginThrottleService) Unlock(ctx context.Context, userID uint) {
+	s.Reset(ctx, LoginAccount(&entity.User{ID: userID}, ""))
+}
+
+func loginAttemptKey(account string) string {
+	return "login-attempt:account:" + account
+}
+
+func loginDelayKey(account string) string {
+	return "login-delay:account:" + account
+}
+
+func loginLockKey(account string) string {
+	return "login-lock:account:" + account
+}
+
+func loginIPAttemptKey(ip string) string {
+	return "login-attempt:ip:" + ip
+}
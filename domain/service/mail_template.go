@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/mail"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+)
+
+// buildActionLink append token as query to configured frontend page
+func buildActionLink(baseURL string, token string) string {
+	link, err := url.Parse(baseURL)
+	if err != nil || baseURL == "" {
+		return token
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
+
+// sendVerificationEmail generate signed email verification token and send it to user email, token
+// is bound to the email so link sent before email changed could not verify the new one
+func sendVerificationEmail(ctx context.Context, mailClient *mail.MailClient, user *entity.User) error {
+	cfg := config.AppConfig
+
+	token, err := helpers.GenerateChallengeTokenWithClaims(
+		user.ID, constant.TokenPurposeEmailVerify, map[string]interface{}{"email_hash": helpers.HashToken(user.Email)},
+		cfg.EmailVerificationTime, helpers.ChallengeKeyRing(),
+	)
+	if err != nil {
+		return err
+	}
+
+	link := html.EscapeString(buildActionLink(cfg.EmailVerificationURL, token))
+	body := fmt.Sprintf(
+		`<p>Hi %s,</p>
+<p>Please confirm your email address by opening the link below:</p>
+<p><a href="%s">%s</a></p>
+<p>The link will expire in %d minutes. If you did not create an account, you can ignore this email.</p>`,
+		html.EscapeString(user.Username), link, link, cfg.EmailVerificationTime,
+	)
+
+	return mailClient.Send(ctx, user.Email, fmt.Sprintf("[%s] Verify your email address", cfg.AppName), body)
+}
+
+// sendPasswordResetEmail send link containing single use reset token to user email
+func sendPasswordResetEmail(ctx context.Context, mailClient *mail.MailClient, user *entity.User, token string) error {
+	cfg := config.AppConfig
+
+	link := html.EscapeString(buildActionLink(cfg.PasswordResetURL, token))
+	body := fmt.Sprintf(
+		`<p>Hi %s,</p>
+<p>We received a request to reset your password. Open the link below to choose a new one:</p>
+<p><a href="%s">%s</a></p>
+<p>The link can only be used once and will expire in %d minutes. If you did not request this, you can ignore this email.</p>`,
+		html.EscapeString(user.Username), link, link, cfg.PasswordResetTime,
+	)
+
+	return mailClient.Send(ctx, user.Email, fmt.Sprintf("[%s] Reset your password", cfg.AppName), body)
+}
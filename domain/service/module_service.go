@@ -19,12 +19,16 @@ type ModuleService interface {
 }
 
 type moduleService struct {
-	repository repository.ModuleRepository
+	repository                   repository.ModuleRepository
+	permissionImplicationService PermissionImplicationService
 }
 
-func NewModuleService(repository repository.ModuleRepository) ModuleService {
+func NewModuleService(
+	repository repository.ModuleRepository, permissionImplicationService PermissionImplicationService,
+) ModuleService {
 	return &moduleService{
-		repository: repository,
+		repository:                   repository,
+		permissionImplicationService: permissionImplicationService,
 	}
 }
 
@@ -153,6 +157,9 @@ func (s *moduleService) UpdateByID(ctx context.Context, input *model.ModuleInput
 		})
 	}
 
+	// Wildcard permission match module by name
+	s.permissionImplicationService.Invalidate(ctx)
+
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 		Status:  fiber.StatusOK,
 		Success: true,
@@ -184,6 +191,8 @@ func (s *moduleService) DeleteByID(ctx context.Context, id uint) helpers.BaseRes
 		})
 	}
 
+	s.permissionImplicationService.Invalidate(ctx)
+
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 		Status:  fiber.StatusOK,
 		Success: true,
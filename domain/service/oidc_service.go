@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/oidc"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+)
+
+var oidcUsernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// OidcService sign user in through external OpenID Connect provider using authorization
+// code flow with PKCE, user is matched by linked provider subject, linked by verified email
+// or provisioned when group map to a role
+type OidcService interface {
+	Login(ctx context.Context, provider string) helpers.BaseResponse
+	Callback(ctx context.Context, provider string, input *model.OidcCallbackInput) helpers.BaseResponse
+}
+
+type oidcService struct {
+	oidcClient             *oidc.Client
+	userRepository         repository.UserRepository
+	userIdentityRepository repository.UserIdentityRepository
+	roleRepository         repository.RoleRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	twoFactorRepository    repository.TwoFactorRepository
+	cacheRedis             *redis.CacheClient
+	tokenDenyListService   TokenDenyListService
+	tokenVersionService    TokenVersionService
+	loginThrottleService   LoginThrottleService
+}
+
+// oidcState is kept in redis between login redirect and callback
+type oidcState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+func NewOidcService(
+	oidcClient *oidc.Client, userRepository repository.UserRepository,
+	userIdentityRepository repository.UserIdentityRepository, roleRepository repository.RoleRepository,
+	refreshTokenRepository repository.RefreshTokenRepository, twoFactorRepository repository.TwoFactorRepository,
+	cacheRedis *redis.CacheClient, tokenDenyListService TokenDenyListService, tokenVersionService TokenVersionService,
+	loginThrottleService LoginThrottleService,
+) OidcService {
+	return &oidcService{
+		oidcClient:             oidcClient,
+		userRepository:         userRepository,
+		userIdentityRepository: userIdentityRepository,
+		roleRepository:         roleRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		twoFactorRepository:    twoFactorRepository,
+		cacheRedis:             cacheRedis,
+		tokenDenyListService:   tokenDenyListService,
+		tokenVersionService:    tokenVersionService,
+		loginThrottleService:   loginThrottleService,
+	}
+}
+
+func (s *oidcService) Login(ctx context.Context, providerName string) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	provider, ok := config.GetOidcProvider(providerName)
+	if !ok {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Identity provider not found",
+		})
+	}
+
+	stateKey := utils.GenerateRandomString(32, true)
+	state := oidcState{
+		Provider:     provider.Name,
+		Nonce:        utils.GenerateRandomString(32, true),
+		CodeVerifier: utils.GenerateRandomString(64, true),
+	}
+
+	authorizationURL, err := s.oidcClient.AuthCodeURL(ctx, provider, stateKey, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadGateway,
+			Success: false,
+			Message: "Identity provider unavailable",
+			Errors:  err,
+		})
+	}
+
+	stateTime := time.Duration(config.AppConfig.OidcStateTime) * time.Minute
+	if err := s.cacheRedis.Set(ctx, constant.CacheKeyOidcState+stateKey, state, stateTime); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error starting login",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Redirect to identity provider",
+		Data:    model.OidcAuthorization{AuthorizationURL: authorizationURL},
+	})
+}
+
+func (s *oidcService) Callback(ctx context.Context, providerName string, input *model.OidcCallbackInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	provider, ok := config.GetOidcProvider(providerName)
+	if !ok {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Identity provider not found",
+		})
+	}
+
+	if input.Error != "" {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusUnauthorized,
+			Success: false,
+			Message: "Login rejected by identity provider",
+			Errors:  input.Error + " " + input.ErrorDescription,
+		})
+	}
+
+	// State is single use, read and deleted atomically so concurrent callback could not both consume it
+	var state oidcState
+	if input.State == "" || s.cacheRedis.GetDelObject(ctx, constant.CacheKeyOidcState+input.State, &state) != nil ||
+		state.Provider != provider.Name {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or expired login state",
+		})
+	}
+
+	rawIDToken, err := s.oidcClient.Exchange(ctx, provider, input.Code, state.CodeVerifier)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusUnauthorized,
+			Success: false,
+			Message: "Failed exchanging authorization code",
+			Errors:  err,
+		})
+	}
+
+	idToken, err := s.oidcClient.VerifyIDToken(ctx, provider, rawIDToken, state.Nonce)
+	if err != nil {
+		helpers.LogSecurityEvent(ctx, "OIDC ID token rejected", map[string]interface{}{
+			"provider": provider.Name,
+			"error":    err.Error(),
+		})
+
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusUnauthorized,
+			Success: false,
+			Message: "Invalid ID token",
+			Errors:  err,
+		})
+	}
+
+	// User could be linked by email, so email not explicitly verified by provider is never trusted
+	if idToken.Email == "" || idToken.EmailVerified == nil || !*idToken.EmailVerified {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Identity provider did not return verified email",
+		})
+	}
+
+	role, err := s.mappedRole(ctx, provider, idToken.Groups)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error resolving role",
+			Errors:  err,
+		})
+	}
+
+	user, err := s.resolveUser(ctx, provider, idToken, role)
+
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "No account could be linked to this identity",
+			Errors:  err,
+		})
+	}
+
+	helpers.LogSecurityEvent(ctx, "User signed in with OIDC provider", map[string]interface{}{
+		"user_id":  user.ID,
+		"provider": provider.Name,
+		"subject":  idToken.Subject,
+	})
+
+	return helpers.LogBaseResponse(&logData, completeLogin(ctx, s.refreshTokenRepository, s.twoFactorRepository, s.loginThrottleService, user, false))
+}
+
+// mappedRole return role of the first configured group the identity belong to, nil when none match
+func (s *oidcService) mappedRole(ctx context.Context, provider *config.OidcProvider, groups []string) (*entity.Role, error) {
+	memberOf := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		memberOf[group] = struct{}{}
+	}
+
+	for _, mapping := range provider.RoleMapping {
+		if _, ok := memberOf[mapping.Group]; ok {
+			return s.roleRepository.FindByName(ctx, mapping.Role)
+		}
+	}
+
+	return nil, nil
+}
+
+// resolveUser find user already linked to identity subject, otherwise link existing user with the
+// same email or provision new one, then remember the link so later login is matched by subject
+func (s *oidcService) resolveUser(
+	ctx context.Context, provider *config.OidcProvider, idToken *oidc.IDToken, role *entity.Role,
+) (*entity.User, error) {
+	if identity, err := s.userIdentityRepository.FindByProviderSubject(ctx, provider.Name, idToken.Subject); err == nil {
+		user, err := s.userRepository.FindByIDWithRole(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return s.link(ctx, provider, idToken, user, role)
+	}
+
+	user, err := s.userRepository.FindByEmailWithRole(ctx, idToken.Email)
+	if err != nil {
+		if user, err = s.provision(ctx, provider, idToken, role); err != nil {
+			return nil, err
+		}
+		if err := s.insertIdentity(ctx, provider, idToken, user); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	// Admin account is never taken over by email alone, it must sign in locally
+	if user.IsAdmin() {
+		helpers.LogSecurityEvent(ctx, "OIDC auto-link to admin account denied", map[string]interface{}{
+			"user_id":  user.ID,
+			"provider": provider.Name,
+			"subject":  idToken.Subject,
+		})
+		return nil, fmt.Errorf("admin account could not be linked by email")
+	}
+
+	// Link is stored before role is synced, so user already linked to other subject is left untouched
+	if err := s.insertIdentity(ctx, provider, idToken, user); err != nil {
+		return nil, err
+	}
+
+	return s.link(ctx, provider, idToken, user, role)
+}
+
+// insertIdentity remember identity subject of user, failing when user already linked to other
+// subject of the same provider
+func (s *oidcService) insertIdentity(
+	ctx context.Context, provider *config.OidcProvider, idToken *oidc.IDToken, user *entity.User,
+) error {
+	if err := s.userIdentityRepository.Insert(ctx, &entity.UserIdentity{
+		UserID:   user.ID,
+		Provider: provider.Name,
+		Subject:  idToken.Subject,
+		Email:    idToken.Email,
+	}); err != nil {
+		return err
+	}
+
+	helpers.LogSecurityEvent(ctx, "User linked to OIDC identity", map[string]interface{}{
+		"user_id":  user.ID,
+		"provider": provider.Name,
+		"subject":  idToken.Subject,
+	})
+
+	return nil
+}
+
+// provision create user for identity seen the first time, only when its group map to a role
+// or provider has default role
+func (s *oidcService) provision(
+	ctx context.Context, provider *config.OidcProvider, idToken *oidc.IDToken, role *entity.Role,
+) (*entity.User, error) {
+	if role == nil && provider.DefaultRole != "" {
+		defaultRole, err := s.roleRepository.FindByName(ctx, provider.DefaultRole)
+		if err != nil {
+			return nil, err
+		}
+		role = defaultRole
+	}
+
+	if role == nil {
+		helpers.LogSecurityEvent(ctx, "OIDC login without matching role denied", map[string]interface{}{
+			"provider": provider.Name,
+			"subject":  idToken.Subject,
+			"email":    idToken.Email,
+		})
+		return nil, fmt.Errorf("no role mapped for identity")
+	}
+
+	user := &entity.User{
+		RoleID:   role.ID,
+		Roles:    []entity.Role{*role},
+		Username: s.availableUsername(ctx, idToken),
+		Email:    idToken.Email,
+		// Password login is not intended, random password only satisfy the column
+		Password:    utils.GenerateRandomString(32, false),
+		ValidatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+
+	if err := s.userRepository.Insert(ctx, user); err != nil {
+		return nil, err
+	}
+
+	helpers.LogSecurityEvent(ctx, "User provisioned from OIDC provider", map[string]interface{}{
+		"user_id":  user.ID,
+		"provider": provider.Name,
+		"subject":  idToken.Subject,
+		"role_id":  role.ID,
+	})
+
+	return s.userRepository.FindByIDWithRole(ctx, user.ID)
+}
+
+// link sign existing user in, primary role is synced from mapped group while other assigned
+// roles are kept, and unverified email is marked verified since provider already verified it
+func (s *oidcService) link(
+	ctx context.Context, provider *config.OidcProvider, idToken *oidc.IDToken, user *entity.User, role *entity.Role,
+) (*entity.User, error) {
+	update := &entity.User{ID: user.ID}
+	roleChanged := role != nil && role.ID != user.RoleID
+
+	if roleChanged {
+		update.RoleID = role.ID
+		update.Roles = []entity.Role{*role}
+		for _, assigned := range user.AllRoles() {
+			if assigned.ID != user.RoleID && assigned.ID != role.ID {
+				update.Roles = append(update.Roles, assigned)
+			}
+		}
+	}
+	if !user.ValidatedAt.Valid {
+		update.ValidatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
+	if !roleChanged && !update.ValidatedAt.Valid {
+		return user, nil
+	}
+
+	if roleChanged {
+		if err := s.userRepository.UpdateWithRoles(ctx, update); err != nil {
+			return nil, err
+		}
+	} else if err := s.userRepository.Update(ctx, update); err != nil {
+		return nil, err
+	}
+
+	// Moving user to other role change the permissions of issued access token
+	if roleChanged {
+		s.tokenVersionService.BumpUser(ctx, user.ID)
+		s.tokenDenyListService.DenyUser(ctx, user.ID)
+
+		helpers.LogSecurityEvent(ctx, "User role synced from OIDC provider groups", map[string]interface{}{
+			"user_id":          user.ID,
+			"provider":         provider.Name,
+			"previous_role_id": user.RoleID,
+			"role_id":          role.ID,
+		})
+	}
+
+	return s.userRepository.FindByIDWithRole(ctx, user.ID)
+}
+
+// availableUsername derive username from preferred username or email, suffixed when already taken
+func (s *oidcService) availableUsername(ctx context.Context, idToken *oidc.IDToken) string {
+	base := idToken.PreferredUsername
+	if base == "" || strings.Contains(base, "@") {
+		base, _, _ = strings.Cut(idToken.Email, "@")
+	}
+
+	base = oidcUsernameInvalidChars.ReplaceAllString(base, "")
+	if base == "" {
+		base = "user"
+	}
+
+	username := base
+	for s.userRepository.UsernameExist(ctx, &entity.User{Username: username}) {
+		username = base + "_" + strings.ToLower(utils.GenerateRandomString(4, true))
+	}
+
+	return username
+}
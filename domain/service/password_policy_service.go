@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/breach"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordPolicyService enforce password policy configured by PASSWORD_* on top of the rule
+// checked per password, it reject password found in breached password list, remember the last
+// PASSWORD_HISTORY hashes of each user so previous password could not be reused, and tell when
+// password is older than PASSWORD_MAX_AGE
+type PasswordPolicyService interface {
+	Validate(ctx context.Context, user *entity.User, password string) []helpers.ValidationError
+	Record(ctx context.Context, userID uint, passwordHash string)
+	IsExpired(user *entity.User) bool
+}
+
+type passwordPolicyService struct {
+	passwordHistoryRepository repository.PasswordHistoryRepository
+	breachLookup              breach.Lookup
+}
+
+// NewPasswordPolicyService create the service, breachLookup could be nil to skip breached password check
+func NewPasswordPolicyService(
+	passwordHistoryRepository repository.PasswordHistoryRepository, breachLookup breach.Lookup,
+) PasswordPolicyService {
+	return &passwordPolicyService{
+		passwordHistoryRepository: passwordHistoryRepository,
+		breachLookup:              breachLookup,
+	}
+}
+
+// Validate return every policy rule broken by password of user, user without id is a new user
+// so only the rule is checked
+func (s *passwordPolicyService) Validate(ctx context.Context, user *entity.User, password string) []helpers.ValidationError {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	cfg := config.AppConfig
+
+	errs := helpers.ValidatePassword(password, user.Username, user.Email)
+
+	if s.breachLookup != nil {
+		// Unreadable list should not block every password change, it is only logged
+		breached, err := s.breachLookup.IsBreached(ctx, password)
+		if err != nil {
+			logData.Message = "Not Passed"
+			logData.Err = err
+		}
+		if breached {
+			errs = append(errs, helpers.ValidationError{
+				Field: "password",
+				Tag:   "breached",
+			})
+		}
+	}
+
+	if user.ID == 0 || cfg.PasswordHistory <= 0 {
+		return errs
+	}
+
+	// Current password is compared too, it might be set before history was recorded
+	hashes := []string{user.Password}
+	histories, err := s.passwordHistoryRepository.FindRecentByUserID(ctx, user.ID, cfg.PasswordHistory)
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+	for _, history := range histories {
+		hashes = append(hashes, history.PasswordHash)
+	}
+
+	for _, hash := range hashes {
+		if hash != "" && bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			errs = append(errs, helpers.ValidationError{
+				Field: "password",
+				Tag:   "reused",
+				Param: strconv.Itoa(cfg.PasswordHistory),
+			})
+			break
+		}
+	}
+
+	return errs
+}
+
+// Record remember newly set password hash of user, dropping hash beyond the configured history
+func (s *passwordPolicyService) Record(ctx context.Context, userID uint, passwordHash string) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	cfg := config.AppConfig
+	if cfg.PasswordHistory <= 0 {
+		return
+	}
+
+	if err := s.passwordHistoryRepository.Insert(ctx, &entity.PasswordHistory{
+		UserID:       userID,
+		PasswordHash: passwordHash,
+	}); err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return
+	}
+
+	if err := s.passwordHistoryRepository.PruneByUserID(ctx, userID, cfg.PasswordHistory); err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+}
+
+// IsExpired report whether user must change password before being let in
+func (s *passwordPolicyService) IsExpired(user *entity.User) bool {
+	cfg := config.AppConfig
+	if cfg.PasswordMaxAge <= 0 {
+		return false
+	}
+
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt.Valid {
+		changedAt = user.PasswordChangedAt.Time
+	}
+
+	return time.Since(changedAt) > time.Duration(cfg.PasswordMaxAge)*24*time.Hour
+}
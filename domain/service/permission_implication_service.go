@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+)
+
+// PermissionImplicationService expand granted permissions with everything they imply, through
+// declared implication and wildcard permission, used by Authorization when matching permission
+type PermissionImplicationService interface {
+	Expand(ctx context.Context, permissions []string) ([]string, error)
+	Invalidate(ctx context.Context)
+}
+
+type permissionImplicationService struct {
+	permissionRepository repository.PermissionRepository
+	cacheRedis           *redis.CacheClient
+}
+
+func NewPermissionImplicationService(
+	permissionRepository repository.PermissionRepository, cacheRedis *redis.CacheClient,
+) PermissionImplicationService {
+	return &permissionImplicationService{
+		permissionRepository: permissionRepository,
+		cacheRedis:           cacheRedis,
+	}
+}
+
+// Expand return granted permissions followed by every permission they imply, directly or
+// through other implied permission
+func (s *permissionImplicationService) Expand(ctx context.Context, permissions []string) ([]string, error) {
+	graph, err := s.graph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(permissions))
+	expanded := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if _, exists := seen[permission]; !exists {
+			seen[permission] = struct{}{}
+			expanded = append(expanded, permission)
+		}
+	}
+
+	// Expanded grow while walked, so implied permission is expanded as well
+	for i := 0; i < len(expanded); i++ {
+		for _, implied := range graph[expanded[i]] {
+			if _, exists := seen[implied]; !exists {
+				seen[implied] = struct{}{}
+				expanded = append(expanded, implied)
+			}
+		}
+	}
+
+	return expanded, nil
+}
+
+func (s *permissionImplicationService) Invalidate(ctx context.Context) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := s.cacheRedis.Del(ctx, constant.CacheKeyPermissionImplications); err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+}
+
+func (s *permissionImplicationService) graph(ctx context.Context) (map[string][]string, error) {
+	var graph map[string][]string
+	if err := s.cacheRedis.GetObject(ctx, constant.CacheKeyPermissionImplications, &graph); err == nil && graph != nil {
+		return graph, nil
+	}
+
+	graph, err := s.permissionRepository.FindImplicationGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cacheRedis.Set(ctx, constant.CacheKeyPermissionImplications, graph, time.Hour); err != nil {
+		logData := helpers.CreateLog(s)
+		logData.Message = "Not Passed"
+		logData.Err = err
+		helpers.LogSystemWithDefer(ctx, &logData)
+	}
+
+	return graph, nil
+}
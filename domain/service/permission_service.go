@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"slices"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
@@ -19,14 +20,26 @@ type PermissionService interface {
 }
 
 type permissionService struct {
-	repository       repository.PermissionRepository
-	moduleRepository repository.ModuleRepository
+	repository                   repository.PermissionRepository
+	moduleRepository             repository.ModuleRepository
+	tokenDenyListService         TokenDenyListService
+	tokenVersionService          TokenVersionService
+	rolePermissionService        RolePermissionService
+	permissionImplicationService PermissionImplicationService
 }
 
-func NewPermissionService(repository repository.PermissionRepository, moduleRepository repository.ModuleRepository) PermissionService {
+func NewPermissionService(
+	repository repository.PermissionRepository, moduleRepository repository.ModuleRepository,
+	tokenDenyListService TokenDenyListService, tokenVersionService TokenVersionService,
+	rolePermissionService RolePermissionService, permissionImplicationService PermissionImplicationService,
+) PermissionService {
 	return &permissionService{
-		repository:       repository,
-		moduleRepository: moduleRepository,
+		repository:                   repository,
+		moduleRepository:             moduleRepository,
+		tokenDenyListService:         tokenDenyListService,
+		tokenVersionService:          tokenVersionService,
+		rolePermissionService:        rolePermissionService,
+		permissionImplicationService: permissionImplicationService,
 	}
 }
 
@@ -117,6 +130,8 @@ func (s *permissionService) Create(ctx context.Context, input *model.PermissionI
 		})
 	}
 
+	s.permissionImplicationService.Invalidate(ctx)
+
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 		Status:  fiber.StatusCreated,
 		Success: true,
@@ -166,6 +181,12 @@ func (s *permissionService) UpdateByID(ctx context.Context, input *model.Permiss
 		})
 	}
 
+	// Permission name is embedded in issued access token and cached role permissions
+	s.rolePermissionService.InvalidateByPermission(ctx, id)
+	s.tokenVersionService.BumpRolesByPermission(ctx, id)
+	s.tokenDenyListService.DenyPermission(ctx, id)
+	s.permissionImplicationService.Invalidate(ctx)
+
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 		Status:  fiber.StatusOK,
 		Success: true,
@@ -188,6 +209,8 @@ func (s *permissionService) DeleteByID(ctx context.Context, id uint) helpers.Bas
 		})
 	}
 
+	s.tokenVersionService.BumpRolesByPermission(ctx, id)
+	s.tokenDenyListService.DenyPermission(ctx, id)
 	if err := s.repository.Delete(ctx, permission); err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
@@ -197,6 +220,9 @@ func (s *permissionService) DeleteByID(ctx context.Context, id uint) helpers.Bas
 		})
 	}
 
+	s.rolePermissionService.InvalidateByPermission(ctx, id)
+	s.permissionImplicationService.Invalidate(ctx)
+
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 		Status:  fiber.StatusOK,
 		Success: true,
@@ -226,6 +252,26 @@ func (s *permissionService) validateEntityInput(ctx context.Context, permission
 		})
 	}
 
+	// Check implied permissions existence, permission implying itself is meaningless
+	if len(permission.Implies) != 0 {
+		impliedIDs := make([]uint, 0, len(permission.Implies))
+		for _, implied := range permission.Implies {
+			impliedIDs = append(impliedIDs, implied.ID)
+		}
+
+		if implies, err := s.repository.FindInID(ctx, impliedIDs); implies == nil || err != nil || len(*implies) != len(impliedIDs) {
+			errs = append(errs, helpers.ValidationError{
+				Field: "implies",
+				Tag:   "not_found",
+			})
+		} else if permission.ID != 0 && slices.Contains(impliedIDs, permission.ID) {
+			errs = append(errs, helpers.ValidationError{
+				Field: "implies",
+				Tag:   "self",
+			})
+		}
+	}
+
 	if len(errs) != 0 {
 		logData.Message = "Validation error"
 		logData.Err = errs
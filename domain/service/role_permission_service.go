@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+// RolePermissionService resolve role permissions through redis cache, used by Authorization
+// in runtime permission mode where access token carry no permission list
+type RolePermissionService interface {
+	GetPermissionNames(ctx context.Context, roleID uint) ([]string, error)
+	InvalidateRole(ctx context.Context, roleID uint)
+	InvalidateByPermission(ctx context.Context, permissionID uint)
+}
+
+type rolePermissionService struct {
+	roleRepository repository.RoleRepository
+	cacheRedis     *redis.CacheClient
+}
+
+func NewRolePermissionService(roleRepository repository.RoleRepository, cacheRedis *redis.CacheClient) RolePermissionService {
+	return &rolePermissionService{
+		roleRepository: roleRepository,
+		cacheRedis:     cacheRedis,
+	}
+}
+
+func (s *rolePermissionService) GetPermissionNames(ctx context.Context, roleID uint) ([]string, error) {
+	cacheKey := rolePermissionCacheKey(roleID)
+
+	var permissions []string
+	if err := s.cacheRedis.GetObject(ctx, cacheKey, &permissions); err == nil && permissions != nil {
+		return permissions, nil
+	}
+
+	permissions, err := s.roleRepository.FindPermissionNamesByID(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cacheRedis.Set(ctx, cacheKey, permissions, time.Hour); err != nil {
+		logData := helpers.CreateLog(s)
+		logData.Message = "Not Passed"
+		logData.Err = err
+		helpers.LogSystemWithDefer(ctx, &logData)
+	}
+
+	return permissions, nil
+}
+
+func (s *rolePermissionService) InvalidateRole(ctx context.Context, roleID uint) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := s.cacheRedis.Del(ctx, rolePermissionCacheKey(roleID)); err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+}
+
+func (s *rolePermissionService) InvalidateByPermission(ctx context.Context, permissionID uint) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	roleIDs, err := s.roleRepository.FindIDsByPermissionID(ctx, permissionID)
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return
+	}
+
+	for _, roleID := range roleIDs {
+		s.InvalidateRole(ctx, roleID)
+	}
+}
+
+func rolePermissionCacheKey(roleID uint) string {
+	return fmt.Sprintf("cache:role-permissions:role-id:%d", roleID)
+}
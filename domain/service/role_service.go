@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"slices"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
@@ -19,14 +20,24 @@ type RoleService interface {
 }
 
 type roleService struct {
-	repository     repository.RoleRepository
-	permissionRepo repository.PermissionRepository
+	repository            repository.RoleRepository
+	permissionRepo        repository.PermissionRepository
+	tokenDenyListService  TokenDenyListService
+	tokenVersionService   TokenVersionService
+	rolePermissionService RolePermissionService
 }
 
-func NewRoleService(repository repository.RoleRepository, permissionRepo repository.PermissionRepository) RoleService {
+func NewRoleService(
+	repository repository.RoleRepository, permissionRepo repository.PermissionRepository,
+	tokenDenyListService TokenDenyListService, tokenVersionService TokenVersionService,
+	rolePermissionService RolePermissionService,
+) RoleService {
 	return &roleService{
-		repository:     repository,
-		permissionRepo: permissionRepo,
+		repository:            repository,
+		permissionRepo:        permissionRepo,
+		tokenDenyListService:  tokenDenyListService,
+		tokenVersionService:   tokenVersionService,
+		rolePermissionService: rolePermissionService,
 	}
 }
 
@@ -44,7 +55,17 @@ func (s *roleService) GetByID(ctx context.Context, id uint) helpers.BaseResponse
 		})
 	}
 
-	roleModel := model.RoleToDetailModel(role)
+	ancestors, err := s.repository.FindAncestors(ctx, id)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error resolving inherited permissions",
+			Errors:  err,
+		})
+	}
+
+	roleModel := model.RoleToDetailModel(role, ancestors)
 
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 		Status:  fiber.StatusOK,
@@ -204,9 +225,24 @@ func (s *roleService) UpdateByID(ctx context.Context, input *model.RoleInput, id
 		})
 	}
 
+	// Replace parents in the database
+	if err := s.repository.ReplaceParentsWithTransaction(ctx, tx, roleEntity, &roleEntity.Parents); err != nil {
+		tx.Rollback() // Rollback on error
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error replacing role parents data",
+			Errors:  err,
+		})
+	}
+
 	// Commit the transaction if all operations succeed
 	tx.Commit()
 
+	// Issued access token and cached role permissions still carry the old permissions,
+	// including those of every role inheriting from this one
+	s.invalidateRole(ctx, id, true)
+
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 		Status:  fiber.StatusOK,
 		Success: true,
@@ -229,6 +265,10 @@ func (s *roleService) DeleteByID(ctx context.Context, id uint) helpers.BaseRespo
 		})
 	}
 
+	// Role inheriting from the deleted one lose its permissions
+	descendantIDs, _ := s.repository.FindDescendantIDs(ctx, id)
+
+	s.tokenDenyListService.DenyRole(ctx, id)
 	if err := s.repository.Delete(ctx, role); err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
@@ -238,6 +278,11 @@ func (s *roleService) DeleteByID(ctx context.Context, id uint) helpers.BaseRespo
 		})
 	}
 
+	s.rolePermissionService.InvalidateRole(ctx, id)
+	for _, descendantID := range descendantIDs {
+		s.invalidateRole(ctx, descendantID, false)
+	}
+
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 		Status:  fiber.StatusOK,
 		Success: true,
@@ -245,6 +290,22 @@ func (s *roleService) DeleteByID(ctx context.Context, id uint) helpers.BaseRespo
 	})
 }
 
+// invalidateRole drop cached permissions, bump token version and revoke access token of
+// role, and when withDescendants also of every role inheriting from it
+func (s *roleService) invalidateRole(ctx context.Context, id uint, withDescendants bool) {
+	roleIDs := []uint{id}
+	if withDescendants {
+		descendantIDs, _ := s.repository.FindDescendantIDs(ctx, id)
+		roleIDs = append(roleIDs, descendantIDs...)
+	}
+
+	for _, roleID := range roleIDs {
+		s.rolePermissionService.InvalidateRole(ctx, roleID)
+		s.tokenVersionService.BumpRole(ctx, roleID)
+		s.tokenDenyListService.DenyRole(ctx, roleID)
+	}
+}
+
 func (s *roleService) validateEntityInput(ctx context.Context, role *entity.Role) interface{} {
 	logData := helpers.CreateLog(s)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -259,6 +320,29 @@ func (s *roleService) validateEntityInput(ctx context.Context, role *entity.Role
 		})
 	}
 
+	// Check parents existence and that none of them already inherit from the role
+	if len(role.Parents) != 0 {
+		parentIDs := make([]uint, 0, len(role.Parents))
+		for _, parent := range role.Parents {
+			parentIDs = append(parentIDs, parent.ID)
+		}
+
+		if parents, err := s.repository.FindByIDs(ctx, parentIDs); parents == nil || err != nil || len(*parents) != len(parentIDs) {
+			errs = append(errs, helpers.ValidationError{
+				Field: "parent_ids",
+				Tag:   "not_found",
+			})
+		} else if role.ID != 0 {
+			ancestorIDs, err := s.repository.FindAncestorIDs(ctx, parentIDs)
+			if err != nil || slices.Contains(parentIDs, role.ID) || slices.Contains(ancestorIDs, role.ID) {
+				errs = append(errs, helpers.ValidationError{
+					Field: "parent_ids",
+					Tag:   "cycle",
+				})
+			}
+		}
+	}
+
 	if len(errs) != 0 {
 		logData.Message = "Validation error"
 		logData.Err = errs
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type SessionService interface {
+	GetAllByUserID(ctx context.Context, userID uint) helpers.BaseResponse
+	RevokeByUUID(ctx context.Context, userID uint, sessionID uuid.UUID) helpers.BaseResponse
+	RevokeAllByUserID(ctx context.Context, userID uint) helpers.BaseResponse
+}
+
+type sessionService struct {
+	refreshTokenRepository repository.RefreshTokenRepository
+	userRepository         repository.UserRepository
+	tokenDenyListService   TokenDenyListService
+}
+
+func NewSessionService(
+	refreshTokenRepository repository.RefreshTokenRepository, userRepository repository.UserRepository,
+	tokenDenyListService TokenDenyListService,
+) SessionService {
+	return &sessionService{
+		refreshTokenRepository: refreshTokenRepository,
+		userRepository:         userRepository,
+		tokenDenyListService:   tokenDenyListService,
+	}
+}
+
+func (s *sessionService) GetAllByUserID(ctx context.Context, userID uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if user, err := s.userRepository.FindByID(ctx, userID); user == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	tokens, err := s.refreshTokenRepository.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error retrieving session data",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Session data found",
+		Data:    model.SessionToListModels(&tokens),
+	})
+}
+
+func (s *sessionService) RevokeByUUID(ctx context.Context, userID uint, sessionID uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	s.tokenDenyListService.DenySession(ctx, userID, sessionID)
+	if err := s.refreshTokenRepository.RevokeSession(ctx, userID, sessionID); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Session not found",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Session successfully revoked",
+	})
+}
+
+func (s *sessionService) RevokeAllByUserID(ctx context.Context, userID uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if user, err := s.userRepository.FindByID(ctx, userID); user == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	s.tokenDenyListService.DenyUser(ctx, userID)
+	if err := s.refreshTokenRepository.RevokeAllByUserID(ctx, userID); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error revoking session",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "All session successfully revoked",
+	})
+}
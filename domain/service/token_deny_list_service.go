@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+)
+
+// TokenDenyListService keep id (jti) of revoked access token in redis until the token expired,
+// so access token stop working right away instead of living until its exp
+type TokenDenyListService interface {
+	IsDenied(ctx context.Context, jti string) bool
+	DenyToken(ctx context.Context, jti string, expiredAt time.Time)
+	DenySession(ctx context.Context, userID uint, sessionID uuid.UUID)
+	DenyUser(ctx context.Context, userID uint)
+	DenyRole(ctx context.Context, roleID uint)
+	DenyPermission(ctx context.Context, permissionID uint)
+}
+
+type tokenDenyListService struct {
+	refreshTokenRepository repository.RefreshTokenRepository
+	cacheRedis             *redis.CacheClient
+}
+
+func NewTokenDenyListService(
+	refreshTokenRepository repository.RefreshTokenRepository, cacheRedis *redis.CacheClient,
+) TokenDenyListService {
+	return &tokenDenyListService{
+		refreshTokenRepository: refreshTokenRepository,
+		cacheRedis:             cacheRedis,
+	}
+}
+
+func (s *tokenDenyListService) IsDenied(ctx context.Context, jti string) bool {
+	if jti == "" {
+		return false
+	}
+
+	exist, err := s.cacheRedis.Exist(ctx, constant.CacheKeyAccessDenyList+jti)
+	if err != nil {
+		// Redis unavailable should not lock every user out, signature and exp still checked
+		helpers.LogSecurityEvent(ctx, "Access token deny list unavailable", err.Error())
+		return false
+	}
+
+	return exist
+}
+
+// DenyToken revoke single access token that is not tracked by refresh token (e.g. impersonation)
+func (s *tokenDenyListService) DenyToken(ctx context.Context, jti string, expiredAt time.Time) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	ttl := time.Until(expiredAt)
+	if jti == "" || ttl <= 0 {
+		return
+	}
+
+	if err := s.cacheRedis.Set(ctx, constant.CacheKeyAccessDenyList+jti, jti, ttl); err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+}
+
+func (s *tokenDenyListService) DenySession(ctx context.Context, userID uint, sessionID uuid.UUID) {
+	tokens, err := s.refreshTokenRepository.FindLiveAccessBySession(ctx, userID, sessionID)
+	s.deny(ctx, tokens, err)
+}
+
+func (s *tokenDenyListService) DenyUser(ctx context.Context, userID uint) {
+	tokens, err := s.refreshTokenRepository.FindLiveAccessByUserID(ctx, userID)
+	s.deny(ctx, tokens, err)
+}
+
+func (s *tokenDenyListService) DenyRole(ctx context.Context, roleID uint) {
+	tokens, err := s.refreshTokenRepository.FindLiveAccessByRoleID(ctx, roleID)
+	s.deny(ctx, tokens, err)
+}
+
+func (s *tokenDenyListService) DenyPermission(ctx context.Context, permissionID uint) {
+	tokens, err := s.refreshTokenRepository.FindLiveAccessByPermissionID(ctx, permissionID)
+	s.deny(ctx, tokens, err)
+}
+
+// deny put every access token on deny list with ttl until its own expiry,
+// so the list never grow beyond currently valid token
+func (s *tokenDenyListService) deny(ctx context.Context, tokens []entity.RefreshToken, err error) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return
+	}
+
+	for _, token := range tokens {
+		ttl := time.Until(token.AccessExpiredAt)
+		if ttl <= 0 {
+			continue
+		}
+
+		if err := s.cacheRedis.Set(ctx, constant.CacheKeyAccessDenyList+token.AccessJTI, token.UserID, ttl); err != nil {
+			logData.Message = "Not Passed"
+			logData.Err = err
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+// TokenVersionService track token version of user, role and client, access token embed the version
+// and is rejected once it is bumped, so changed permission take effect without waiting exp
+type TokenVersionService interface {
+	IsCurrent(ctx context.Context, userID uint, userVersion uint, roleVersions map[uint]uint) bool
+	IsClientCurrent(ctx context.Context, clientID string, clientVersion uint) bool
+	BumpUser(ctx context.Context, userID uint)
+	BumpRole(ctx context.Context, roleID uint)
+	BumpRolesByPermission(ctx context.Context, permissionID uint)
+	BumpClient(ctx context.Context, clientID string)
+}
+
+type tokenVersionService struct {
+	userRepository   repository.UserRepository
+	roleRepository   repository.RoleRepository
+	clientRepository repository.ClientRepository
+	cacheRedis       *redis.CacheClient
+}
+
+func NewTokenVersionService(
+	userRepository repository.UserRepository, roleRepository repository.RoleRepository,
+	clientRepository repository.ClientRepository, cacheRedis *redis.CacheClient,
+) TokenVersionService {
+	return &tokenVersionService{
+		userRepository:   userRepository,
+		roleRepository:   roleRepository,
+		clientRepository: clientRepository,
+		cacheRedis:       cacheRedis,
+	}
+}
+
+// IsCurrent check user version and version of every role embedded in token, roleVersions is
+// keyed by role id
+func (s *tokenVersionService) IsCurrent(ctx context.Context, userID uint, userVersion uint, roleVersions map[uint]uint) bool {
+	current, err := s.version(ctx, userVersionCacheKey(userID), func() (uint, error) {
+		return s.userRepository.FindTokenVersion(ctx, userID)
+	})
+	if err != nil || current != userVersion {
+		return false
+	}
+
+	// Token issued before role was embedded only carry user version, so nothing else to check
+	for roleID, roleVersion := range roleVersions {
+		current, err = s.version(ctx, roleVersionCacheKey(roleID), func() (uint, error) {
+			return s.roleRepository.FindTokenVersion(ctx, roleID)
+		})
+		if err != nil || current != roleVersion {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsClientCurrent also fail for deleted client, since its version could no longer be found
+func (s *tokenVersionService) IsClientCurrent(ctx context.Context, clientID string, clientVersion uint) bool {
+	current, err := s.version(ctx, clientVersionCacheKey(clientID), func() (uint, error) {
+		return s.clientRepository.FindTokenVersion(ctx, clientID)
+	})
+
+	return err == nil && current == clientVersion
+}
+
+func (s *tokenVersionService) BumpUser(ctx context.Context, userID uint) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	version, err := s.userRepository.IncrementTokenVersion(ctx, userID)
+	s.store(ctx, &logData, userVersionCacheKey(userID), version, err)
+}
+
+func (s *tokenVersionService) BumpRole(ctx context.Context, roleID uint) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	version, err := s.roleRepository.IncrementTokenVersion(ctx, roleID)
+	s.store(ctx, &logData, roleVersionCacheKey(roleID), version, err)
+}
+
+func (s *tokenVersionService) BumpRolesByPermission(ctx context.Context, permissionID uint) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	roleIDs, err := s.roleRepository.FindIDsByPermissionID(ctx, permissionID)
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return
+	}
+
+	for _, roleID := range roleIDs {
+		s.BumpRole(ctx, roleID)
+	}
+}
+
+func (s *tokenVersionService) BumpClient(ctx context.Context, clientID string) {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	version, err := s.clientRepository.IncrementTokenVersion(ctx, clientID)
+	s.store(ctx, &logData, clientVersionCacheKey(clientID), version, err)
+}
+
+// version read version from cache, falling back to database and caching the result
+// for as long as an access token live. Cache is only filled when still empty, so version
+// stored by concurrent bump is never overwritten by the older one read from database
+func (s *tokenVersionService) version(ctx context.Context, key string, find func() (uint, error)) (uint, error) {
+	if version, ok := s.cached(ctx, key); ok {
+		return version, nil
+	}
+
+	version, err := find()
+	if err != nil {
+		return 0, err
+	}
+
+	stored, err := s.cacheRedis.SetNX(ctx, key, version, time.Duration(config.AppConfig.JwtAccessTime)*time.Minute)
+	if err == nil && !stored {
+		if current, ok := s.cached(ctx, key); ok {
+			return current, nil
+		}
+	}
+
+	return version, nil
+}
+
+func (s *tokenVersionService) cached(ctx context.Context, key string) (uint, bool) {
+	data, err := s.cacheRedis.Get(ctx, key, nil)
+	if err != nil {
+		return 0, false
+	}
+
+	version, err := strconv.ParseUint(data, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint(version), true
+}
+
+// store write bumped version straight into cache so token checked right after
+// the bump never see the old version
+func (s *tokenVersionService) store(ctx context.Context, logData *helpers.Log, key string, version uint, err error) {
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return
+	}
+
+	if err := s.cacheRedis.Set(ctx, key, version, time.Duration(config.AppConfig.JwtAccessTime)*time.Minute); err != nil {
+		// Stale cache would keep accepting old token, drop it so next check read database
+		s.cacheRedis.Del(ctx, key)
+		logData.Message = "Not Passed"
+		logData.Err = err
+	}
+}
+
+func userVersionCacheKey(userID uint) string {
+	return fmt.Sprintf("token-version:user-id:%d", userID)
+}
+
+func roleVersionCacheKey(roleID uint) string {
+	return fmt.Sprintf("token-version:role-id:%d", roleID)
+}
+
+func clientVersionCacheKey(clientID string) string {
+	return fmt.Sprintf("token-version:client-id:%s", clientID)
+}
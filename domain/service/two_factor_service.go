@@ -0,0 +1,468 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const totalRecoveryCodes = 10
+
+type TwoFactorService interface {
+	Generate(ctx context.Context, userID uint) helpers.BaseResponse
+	Confirm(ctx context.Context, input *model.TwoFactorCodeInput, userID uint) helpers.BaseResponse
+	Disable(ctx context.Context, input *model.TwoFactorCodeInput, userID uint) helpers.BaseResponse
+	RegenerateRecoveryCodes(ctx context.Context, input *model.TwoFactorCodeInput, userID uint) helpers.BaseResponse
+	Enroll(ctx context.Context, input *model.TwoFactorEnrollInput) helpers.BaseResponse
+	Verify(ctx context.Context, input *model.TwoFactorVerifyInput) helpers.BaseResponse
+}
+
+type twoFactorService struct {
+	repository             repository.TwoFactorRepository
+	userRepository         repository.UserRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	loginThrottleService   LoginThrottleService
+}
+
+func NewTwoFactorService(
+	repository repository.TwoFactorRepository, userRepository repository.UserRepository,
+	refreshTokenRepository repository.RefreshTokenRepository, loginThrottleService LoginThrottleService,
+) TwoFactorService {
+	return &twoFactorService{
+		repository:             repository,
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		loginThrottleService:   loginThrottleService,
+	}
+}
+
+func (s *twoFactorService) Generate(ctx context.Context, userID uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, err := s.userRepository.FindByID(ctx, userID)
+	if user == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, s.generateSecret(ctx, user))
+}
+
+func (s *twoFactorService) Confirm(ctx context.Context, input *model.TwoFactorCodeInput, userID uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	twoFactor, err := s.repository.FindByUserID(ctx, userID)
+	if twoFactor == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Two-factor secret not generated yet",
+			Errors:  err,
+		})
+	}
+
+	if twoFactor.IsEnabled() {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusConflict,
+			Success: false,
+			Message: "Two-factor authentication already enabled",
+		})
+	}
+
+	recoveryCodes, err := s.enable(ctx, twoFactor, input.Code)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid two-factor code",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Two-factor authentication successfully enabled",
+		Data:    &model.TwoFactorRecoveryCodes{RecoveryCodes: recoveryCodes},
+	})
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, input *model.TwoFactorCodeInput, userID uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, err := s.userRepository.FindByIDWithRole(ctx, userID)
+	if user == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	if user.RequireTwoFactor() {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Two-factor authentication is enforced for your role",
+		})
+	}
+
+	twoFactor, err := s.repository.FindByUserID(ctx, userID)
+	if !twoFactor.IsEnabled() || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Two-factor authentication not enabled",
+			Errors:  err,
+		})
+	}
+
+	if !s.verifyCode(ctx, twoFactor, input.Code, true) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid two-factor code",
+		})
+	}
+
+	if err := s.repository.DeleteByUserID(ctx, userID); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error disabling two-factor authentication",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Two-factor authentication successfully disabled",
+	})
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, input *model.TwoFactorCodeInput, userID uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	twoFactor, err := s.repository.FindByUserID(ctx, userID)
+	if !twoFactor.IsEnabled() || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Two-factor authentication not enabled",
+			Errors:  err,
+		})
+	}
+
+	// Only authenticator code accepted, so lost recovery codes could not be used to mint new ones
+	if !s.verifyCode(ctx, twoFactor, input.Code, false) {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid two-factor code",
+		})
+	}
+
+	recoveryCodes, err := s.generateRecoveryCodes(ctx, twoFactor)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error generating recovery codes",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Recovery codes successfully generated",
+		Data:    &model.TwoFactorRecoveryCodes{RecoveryCodes: recoveryCodes},
+	})
+}
+
+func (s *twoFactorService) Enroll(ctx context.Context, input *model.TwoFactorEnrollInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, _, err := s.validateChallenge(ctx, input.ChallengeToken, constant.TokenPurposeTwoFactorEnroll)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Invalid token",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, s.generateSecret(ctx, user))
+}
+
+func (s *twoFactorService) Verify(ctx context.Context, input *model.TwoFactorVerifyInput) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, claim, err := s.validateChallenge(ctx, input.ChallengeToken, constant.TokenPurposeTwoFactorVerify, constant.TokenPurposeTwoFactorEnroll)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusForbidden,
+			Success: false,
+			Message: "Invalid token",
+			Errors:  err,
+		})
+	}
+
+	// Failed code count toward the same lockout as failed password, so code could not be
+	// guessed within the challenge window after password already accepted
+	ipAddress, _ := ctx.Value(constant.CtxKeyIPAddress).(string)
+	account := LoginAccount(user, "")
+	if retryAfter, locked := s.loginThrottleService.Check(ctx, account, ipAddress); retryAfter > 0 {
+		status := fiber.StatusTooManyRequests
+		message := "Too many failed login attempts, please try again later"
+		if locked {
+			status = fiber.StatusLocked
+			message = "Account temporarily locked due to too many failed login attempts"
+		}
+
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  status,
+			Success: false,
+			Message: message,
+			Data:    &model.LoginThrottle{RetryAfter: int(math.Ceil(retryAfter.Seconds()))},
+		})
+	}
+
+	twoFactor, err := s.repository.FindByUserID(ctx, user.ID)
+	if twoFactor == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Two-factor secret not generated yet",
+			Errors:  err,
+		})
+	}
+
+	var recoveryCodes []string
+	if twoFactor.IsEnabled() {
+		if !s.verifyCode(ctx, twoFactor, input.Code, true) {
+			s.loginThrottleService.RecordFailure(ctx, account, ipAddress, user.ID)
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid two-factor code",
+			})
+		}
+	} else {
+		// Enrollment enforced by role is completed in the same step as login
+		recoveryCodes, err = s.enable(ctx, twoFactor, input.Code)
+		if err != nil {
+			s.loginThrottleService.RecordFailure(ctx, account, ipAddress, user.ID)
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid two-factor code",
+				Errors:  err,
+			})
+		}
+	}
+
+	// Login with expired password continue with password change, token pair is issued by it
+	if passwordExpired, _ := claim["password_expired"].(bool); passwordExpired {
+		return helpers.LogBaseResponse(&logData, passwordChangeChallenge(user, recoveryCodes))
+	}
+
+	tokens, err := generateTokenPair(ctx, s.refreshTokenRepository, user, nil)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "failed generating token",
+			Errors:  err,
+		})
+	}
+
+	s.loginThrottleService.Reset(ctx, account)
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: fmt.Sprintf("%s user successfully login", user.Username),
+		Data: &model.TwoFactorLogin{
+			AllToken:      *tokens,
+			RecoveryCodes: recoveryCodes,
+		},
+	})
+}
+
+// generateSecret create (or replace pending) secret for user, enabled secret must be disabled first
+func (s *twoFactorService) generateSecret(ctx context.Context, user *entity.User) helpers.BaseResponse {
+	cfg := config.AppConfig
+
+	twoFactor, _ := s.repository.FindByUserID(ctx, user.ID)
+	if twoFactor.IsEnabled() {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusConflict,
+			Success: false,
+			Message: "Two-factor authentication already enabled",
+		}
+	}
+
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error generating two-factor secret",
+			Errors:  err,
+		}
+	}
+
+	if twoFactor == nil {
+		err = s.repository.Insert(ctx, &entity.UserTwoFactor{UserID: user.ID, Secret: secret})
+	} else {
+		twoFactor.Secret = secret
+		twoFactor.LastUsedStep = 0
+		err = s.repository.Update(ctx, twoFactor)
+	}
+
+	if err != nil {
+		return helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error saving two-factor secret",
+			Errors:  err,
+		}
+	}
+
+	issuer := cfg.TwoFactorIssuer
+	if issuer == "" {
+		issuer = cfg.AppName
+	}
+
+	return helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Two-factor secret successfully generated",
+		Data: &model.TwoFactorSecret{
+			Secret: secret,
+			URI:    helpers.GenerateTOTPURI(issuer, user.Email, secret),
+		},
+	}
+}
+
+// enable confirm pending secret with authenticator code then issue first batch of recovery codes
+func (s *twoFactorService) enable(ctx context.Context, twoFactor *entity.UserTwoFactor, code string) ([]string, error) {
+	if !s.verifyCode(ctx, twoFactor, code, false) {
+		return nil, fmt.Errorf("invalid two-factor code")
+	}
+
+	twoFactor.EnabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.repository.Update(ctx, twoFactor); err != nil {
+		return nil, err
+	}
+
+	return s.generateRecoveryCodes(ctx, twoFactor)
+}
+
+// verifyCode check authenticator code, and when allowed fallback to single use recovery code
+func (s *twoFactorService) verifyCode(ctx context.Context, twoFactor *entity.UserTwoFactor, code string, allowRecovery bool) bool {
+	if step, ok := helpers.ValidateTOTP(twoFactor.Secret, code, time.Now()); ok {
+		// Reject replay of code from the same or older time step
+		if step <= twoFactor.LastUsedStep {
+			return false
+		}
+
+		twoFactor.LastUsedStep = step
+		return s.repository.Update(ctx, twoFactor) == nil
+	}
+
+	if !allowRecovery {
+		return false
+	}
+
+	code = strings.TrimSpace(code)
+	for _, recoveryCode := range twoFactor.RecoveryCodes {
+		if err := bcrypt.CompareHashAndPassword([]byte(recoveryCode.CodeHash), []byte(code)); err == nil {
+			return s.repository.MarkRecoveryCodeUsed(ctx, &recoveryCode) == nil
+		}
+	}
+
+	return false
+}
+
+func (s *twoFactorService) generateRecoveryCodes(ctx context.Context, twoFactor *entity.UserTwoFactor) ([]string, error) {
+	recoveryCodes := make([]string, 0, totalRecoveryCodes)
+	entities := make([]entity.TwoFactorRecoveryCode, 0, totalRecoveryCodes)
+
+	for i := 0; i < totalRecoveryCodes; i++ {
+		code := utils.GenerateRandomString(10, true)
+
+		hashedCode, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+
+		recoveryCodes = append(recoveryCodes, code)
+		entities = append(entities, entity.TwoFactorRecoveryCode{CodeHash: string(hashedCode)})
+	}
+
+	if err := s.repository.ReplaceRecoveryCodes(ctx, twoFactor, &entities); err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// validateChallenge parse challenge token issued by login and make sure it issued for one of the purposes,
+// claims are returned so caller could continue the login step carried by the token
+func (s *twoFactorService) validateChallenge(
+	ctx context.Context, challengeToken string, purposes ...string,
+) (*entity.User, map[string]interface{}, error) {
+	claim, err := helpers.ValidateToken(challengeToken, helpers.ChallengeKeyRing())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	purpose, _ := claim["purpose"].(string)
+	allowed := false
+	for _, p := range purposes {
+		if purpose == p {
+			allowed = true
+			break
+		}
+	}
+
+	if !allowed {
+		return nil, nil, fmt.Errorf("token is not issued for this purpose")
+	}
+
+	userID, ok := claim["sub"].(float64)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid token subject")
+	}
+
+	user, err := s.userRepository.FindByIDWithRole(ctx, uint(userID))
+	return user, claim, err
+}
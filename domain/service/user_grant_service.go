@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+// UserGrantService manage role or permission granted to user for limited time, ProcessDue is
+// run periodically by grant worker to apply grant that start or end
+type UserGrantService interface {
+	GetAllByUserID(ctx context.Context, userID uint) helpers.BaseResponse
+	Create(ctx context.Context, input *model.UserGrantInput, userID uint, grantedBy uint) helpers.BaseResponse
+	RevokeByUUID(ctx context.Context, userID uint, uuid uuid.UUID) helpers.BaseResponse
+	ProcessDue(ctx context.Context) error
+}
+
+type userGrantService struct {
+	repository             repository.UserGrantRepository
+	userRepository         repository.UserRepository
+	roleRepository         repository.RoleRepository
+	permissionRepository   repository.PermissionRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	tokenDenyListService   TokenDenyListService
+	tokenVersionService    TokenVersionService
+}
+
+func NewUserGrantService(
+	repository repository.UserGrantRepository, userRepository repository.UserRepository,
+	roleRepository repository.RoleRepository, permissionRepository repository.PermissionRepository,
+	refreshTokenRepository repository.RefreshTokenRepository, tokenDenyListService TokenDenyListService,
+	tokenVersionService TokenVersionService,
+) UserGrantService {
+	return &userGrantService{
+		repository:             repository,
+		userRepository:         userRepository,
+		roleRepository:         roleRepository,
+		permissionRepository:   permissionRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		tokenDenyListService:   tokenDenyListService,
+		tokenVersionService:    tokenVersionService,
+	}
+}
+
+func (s *userGrantService) GetAllByUserID(ctx context.Context, userID uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if user, err := s.userRepository.FindByID(ctx, userID); user == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	grants, err := s.repository.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error retrieving grant data",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Grant data found",
+		Data:    model.UserGrantToListModels(grants),
+	})
+}
+
+func (s *userGrantService) Create(ctx context.Context, input *model.UserGrantInput, userID uint, grantedBy uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if user, err := s.userRepository.FindByID(ctx, userID); user == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	grant := input.ToEntity(userID, grantedBy)
+
+	if err := s.validateEntityInput(ctx, grant); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+		})
+	}
+
+	// Grant starting right away is in effect once created, so grant job has nothing to apply
+	now := time.Now()
+	active := grant.IsActive(now)
+	if active {
+		grant.ActivatedAt.Time = now
+		grant.ActivatedAt.Valid = true
+	}
+
+	if err := s.repository.Insert(ctx, grant); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating data",
+			Errors:  err,
+		})
+	}
+
+	// Existing token is refreshed to carry the new grant
+	if active {
+		s.tokenVersionService.BumpUser(ctx, userID)
+	}
+
+	helpers.LogSecurityEvent(ctx, "User grant created", grantEventData(grant))
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Grant successfully created",
+		Data:    model.UserGrantToListModel(grant),
+	})
+}
+
+// RevokeByUUID end grant before it expire, session of user is revoked when the grant already in effect
+func (s *userGrantService) RevokeByUUID(ctx context.Context, userID uint, uuid uuid.UUID) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	grant, err := s.repository.FindByUUID(ctx, userID, uuid)
+	if err != nil || grant == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Grant not found",
+			Errors:  err,
+		})
+	}
+
+	if err := s.repository.Delete(ctx, grant); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error deleting data",
+			Errors:  err,
+		})
+	}
+
+	if grant.IsActive(time.Now()) {
+		if err := s.revokeAccess(ctx, userID); err != nil {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error revoking session",
+				Errors:  err,
+			})
+		}
+	}
+
+	helpers.LogSecurityEvent(ctx, "User grant revoked", grantEventData(grant))
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Grant successfully revoked",
+	})
+}
+
+// ProcessDue apply every grant that started or ended since last run, user whose grant started is
+// asked to refresh token while user whose grant ended lose every session
+func (s *userGrantService) ProcessDue(ctx context.Context) error {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	now := time.Now()
+
+	activated, err := s.repository.FindDueActivation(ctx, now)
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	if len(*activated) != 0 {
+		if err := s.repository.MarkActivated(ctx, grantIDs(*activated), now); err != nil {
+			logData.Message = "Not Passed"
+			logData.Err = err
+			return err
+		}
+
+		for _, userID := range grantUserIDs(*activated) {
+			s.tokenVersionService.BumpUser(ctx, userID)
+		}
+
+		for i := range *activated {
+			helpers.LogSecurityEvent(ctx, "User grant activated", grantEventData(&(*activated)[i]))
+		}
+	}
+
+	expired, err := s.repository.FindDueExpiry(ctx, now)
+	if err != nil {
+		logData.Message = "Not Passed"
+		logData.Err = err
+		return err
+	}
+
+	if len(*expired) != 0 {
+		if err := s.repository.MarkExpired(ctx, grantIDs(*expired), now); err != nil {
+			logData.Message = "Not Passed"
+			logData.Err = err
+			return err
+		}
+
+		for _, userID := range grantUserIDs(*expired) {
+			if err := s.revokeAccess(ctx, userID); err != nil {
+				logData.Message = "Not Passed"
+				logData.Err = err
+			}
+		}
+
+		for i := range *expired {
+			helpers.LogSecurityEvent(ctx, "User grant expired", grantEventData(&(*expired)[i]))
+		}
+	}
+
+	return nil
+}
+
+// revokeAccess reject every token of user, so access given by the grant could not outlive it
+func (s *userGrantService) revokeAccess(ctx context.Context, userID uint) error {
+	s.tokenVersionService.BumpUser(ctx, userID)
+	s.tokenDenyListService.DenyUser(ctx, userID)
+
+	return s.refreshTokenRepository.RevokeAllByUserID(ctx, userID)
+}
+
+func (s *userGrantService) validateEntityInput(ctx context.Context, grant *entity.UserGrant) interface{} {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	errors := []helpers.ValidationError{}
+
+	if grant.RoleID != nil {
+		if role, err := s.roleRepository.FindByID(ctx, *grant.RoleID); role == nil || err != nil {
+			errors = append(errors, helpers.ValidationError{
+				Field: "role_id",
+				Tag:   "not_found",
+			})
+		}
+	}
+
+	if grant.PermissionID != nil {
+		if permission, err := s.permissionRepository.FindByID(ctx, *grant.PermissionID); permission == nil || err != nil {
+			errors = append(errors, helpers.ValidationError{
+				Field: "permission_id",
+				Tag:   "not_found",
+			})
+		}
+	}
+
+	if grant.ValidUntil.Valid {
+		if !grant.ValidUntil.Time.After(grant.ValidFrom) {
+			errors = append(errors, helpers.ValidationError{
+				Field: "valid_until",
+				Tag:   "gtfield",
+				Param: "valid_from",
+			})
+		} else if !grant.ValidUntil.Time.After(time.Now()) {
+			errors = append(errors, helpers.ValidationError{
+				Field: "valid_until",
+				Tag:   "future",
+			})
+		}
+	}
+
+	if len(errors) > 0 {
+		logData.Message = "Validation error"
+		logData.Err = errors
+		return errors
+	}
+	return nil
+}
+
+func grantEventData(grant *entity.UserGrant) map[string]interface{} {
+	data := map[string]interface{}{
+		"user_id":    grant.UserID,
+		"grant_id":   grant.UUID,
+		"valid_from": grant.ValidFrom,
+	}
+
+	if grant.RoleID != nil {
+		data["role_id"] = *grant.RoleID
+	}
+	if grant.PermissionID != nil {
+		data["permission_id"] = *grant.PermissionID
+	}
+	if grant.ValidUntil.Valid {
+		data["valid_until"] = grant.ValidUntil.Time
+	}
+
+	return data
+}
+
+func grantIDs(grants []entity.UserGrant) []uint {
+	ids := make([]uint, 0, len(grants))
+	for _, grant := range grants {
+		ids = append(ids, grant.ID)
+	}
+
+	return ids
+}
+
+// grantUserIDs return distinct owner of grants, so user with several due grant is handled once
+func grantUserIDs(grants []entity.UserGrant) []uint {
+	seen := map[uint]struct{}{}
+	ids := []uint{}
+	for _, grant := range grants {
+		if _, exists := seen[grant.UserID]; !exists {
+			seen[grant.UserID] = struct{}{}
+			ids = append(ids, grant.UserID)
+		}
+	}
+
+	return ids
+}
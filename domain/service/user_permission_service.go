@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+// UserPermissionService manage permission allowed or denied to single user on top of its roles
+type UserPermissionService interface {
+	GetAllByUserID(ctx context.Context, userID uint) helpers.BaseResponse
+	Create(ctx context.Context, input *model.UserPermissionInput, userID uint, grantedBy uint) helpers.BaseResponse
+	UpdateByPermissionID(
+		ctx context.Context, input *model.UserPermissionUpdateInput, userID uint, permissionID uint, grantedBy uint,
+	) helpers.BaseResponse
+	DeleteByPermissionID(ctx context.Context, userID uint, permissionID uint) helpers.BaseResponse
+}
+
+type userPermissionService struct {
+	repository           repository.UserPermissionRepository
+	userRepository       repository.UserRepository
+	permissionRepository repository.PermissionRepository
+	tokenDenyListService TokenDenyListService
+	tokenVersionService  TokenVersionService
+}
+
+func NewUserPermissionService(
+	repository repository.UserPermissionRepository, userRepository repository.UserRepository,
+	permissionRepository repository.PermissionRepository, tokenDenyListService TokenDenyListService,
+	tokenVersionService TokenVersionService,
+) UserPermissionService {
+	return &userPermissionService{
+		repository:           repository,
+		userRepository:       userRepository,
+		permissionRepository: permissionRepository,
+		tokenDenyListService: tokenDenyListService,
+		tokenVersionService:  tokenVersionService,
+	}
+}
+
+func (s *userPermissionService) GetAllByUserID(ctx context.Context, userID uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if user, err := s.userRepository.FindByID(ctx, userID); user == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	overrides, err := s.repository.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error retrieving permission override data",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Permission override data found",
+		Data:    model.UserPermissionToListModels(overrides),
+	})
+}
+
+func (s *userPermissionService) Create(ctx context.Context, input *model.UserPermissionInput, userID uint, grantedBy uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if user, err := s.userRepository.FindByID(ctx, userID); user == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	override := input.ToEntity(userID, grantedBy)
+
+	if err := s.validateEntityInput(ctx, override); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+		})
+	}
+
+	if err := s.repository.Insert(ctx, override); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error creating data",
+			Errors:  err,
+		})
+	}
+
+	s.refreshUserToken(ctx, userID)
+
+	helpers.LogSecurityEvent(ctx, "User permission override created", overrideEventData(override))
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusCreated,
+		Success: true,
+		Message: "Permission override successfully created",
+	})
+}
+
+func (s *userPermissionService) UpdateByPermissionID(
+	ctx context.Context, input *model.UserPermissionUpdateInput, userID uint, permissionID uint, grantedBy uint,
+) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if override, err := s.repository.FindByPermissionID(ctx, userID, permissionID); override == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Permission override not found",
+			Errors:  err,
+		})
+	}
+
+	override := input.ToEntity(userID, permissionID, grantedBy)
+
+	if err := s.repository.Update(ctx, override); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+			Errors:  err,
+		})
+	}
+
+	s.refreshUserToken(ctx, userID)
+
+	helpers.LogSecurityEvent(ctx, "User permission override updated", overrideEventData(override))
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Permission override successfully updated",
+	})
+}
+
+func (s *userPermissionService) DeleteByPermissionID(ctx context.Context, userID uint, permissionID uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	if err := s.repository.Delete(ctx, userID, permissionID); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "Permission override not found",
+			Errors:  err,
+		})
+	}
+
+	s.refreshUserToken(ctx, userID)
+
+	helpers.LogSecurityEvent(ctx, "User permission override deleted", map[string]interface{}{
+		"user_id":       userID,
+		"permission_id": permissionID,
+	})
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Permission override successfully deleted",
+	})
+}
+
+// refreshUserToken reject issued access token of user, so it is refreshed with the new permissions
+func (s *userPermissionService) refreshUserToken(ctx context.Context, userID uint) {
+	s.tokenVersionService.BumpUser(ctx, userID)
+	s.tokenDenyListService.DenyUser(ctx, userID)
+}
+
+func (s *userPermissionService) validateEntityInput(ctx context.Context, override *entity.UserPermission) interface{} {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	errors := []helpers.ValidationError{}
+
+	if permission, err := s.permissionRepository.FindByID(ctx, override.PermissionID); permission == nil || err != nil {
+		errors = append(errors, helpers.ValidationError{
+			Field: "permission_id",
+			Tag:   "not_found",
+		})
+	}
+
+	if existing, err := s.repository.FindByPermissionID(ctx, override.UserID, override.PermissionID); existing != nil && err == nil {
+		errors = append(errors, helpers.ValidationError{
+			Field: "permission_id",
+			Tag:   "duplicate",
+		})
+	}
+
+	if len(errors) > 0 {
+		logData.Message = "Validation error"
+		logData.Err = errors
+		return errors
+	}
+	return nil
+}
+
+func overrideEventData(override *entity.UserPermission) map[string]interface{} {
+	return map[string]interface{}{
+		"user_id":       override.UserID,
+		"permission_id": override.PermissionID,
+		"effect":        override.Effect,
+		"reason":        override.Reason,
+	}
+}
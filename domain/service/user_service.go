@@ -2,17 +2,22 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
+	"math"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/repository"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/mail"
 	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/redis"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
 	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService interface {
@@ -23,22 +28,41 @@ type UserService interface {
 	UpdateByID(ctx context.Context, input *model.UserUpdateInput, id uint) helpers.BaseResponse
 	ChangePassByID(ctx context.Context, input *model.ChangePasswordInput, id uint) helpers.BaseResponse
 	DeleteByID(ctx context.Context, id uint) helpers.BaseResponse
+	UnlockByID(ctx context.Context, id uint) helpers.BaseResponse
+	ApproveByID(ctx context.Context, id uint) helpers.BaseResponse
+	UpdateProfileByID(ctx context.Context, input *model.ProfileUpdateInput, id uint) helpers.BaseResponse
+	ChangeOwnPasswordByID(ctx context.Context, input *model.ChangeOwnPasswordInput, id uint) helpers.BaseResponse
+	GetPermissionsByID(ctx context.Context, id uint) helpers.BaseResponse
 }
 
 type userService struct {
-	repository     repository.UserRepository
-	roleRepository repository.RoleRepository
-	cacheRedis     *redis.CacheClient
+	repository             repository.UserRepository
+	roleRepository         repository.RoleRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	cacheRedis             *redis.CacheClient
+	mailClient             *mail.MailClient
+	tokenDenyListService   TokenDenyListService
+	tokenVersionService    TokenVersionService
+	loginThrottleService   LoginThrottleService
+	passwordPolicyService  PasswordPolicyService
 }
 
 func NewUserService(
 	repository repository.UserRepository, roleRepository repository.RoleRepository,
-	cacheRedis *redis.CacheClient,
+	refreshTokenRepository repository.RefreshTokenRepository, cacheRedis *redis.CacheClient, mailClient *mail.MailClient,
+	tokenDenyListService TokenDenyListService, tokenVersionService TokenVersionService,
+	loginThrottleService LoginThrottleService, passwordPolicyService PasswordPolicyService,
 ) UserService {
 	return &userService{
-		repository:     repository,
-		roleRepository: roleRepository,
-		cacheRedis:     cacheRedis,
+		repository:             repository,
+		roleRepository:         roleRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		cacheRedis:             cacheRedis,
+		mailClient:             mailClient,
+		tokenDenyListService:   tokenDenyListService,
+		tokenVersionService:    tokenVersionService,
+		loginThrottleService:   loginThrottleService,
+		passwordPolicyService:  passwordPolicyService,
 	}
 }
 
@@ -158,6 +182,15 @@ func (s *userService) Create(ctx context.Context, input *model.UserInput) helper
 		})
 	}
 
+	if errs := s.passwordPolicyService.Validate(ctx, userEntity, input.Password); len(errs) > 0 {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  errs,
+		})
+	}
+
 	if err := s.ValidateEntityInput(ctx, userEntity); err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusBadRequest,
@@ -167,6 +200,7 @@ func (s *userService) Create(ctx context.Context, input *model.UserInput) helper
 		})
 	}
 
+	userEntity.PasswordChangedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	if err := s.repository.Insert(ctx, userEntity); err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
@@ -176,6 +210,14 @@ func (s *userService) Create(ctx context.Context, input *model.UserInput) helper
 		})
 	}
 
+	// Password is hashed by the entity hook on insert
+	s.passwordPolicyService.Record(ctx, userEntity.ID, userEntity.Password)
+
+	// Failing to deliver mail should not fail user creation, user could request resend later
+	if err := sendVerificationEmail(ctx, s.mailClient, userEntity); err != nil {
+		log.Printf("failed sending verification email to user %d: %v", userEntity.ID, err)
+	}
+
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 		Status:  fiber.StatusCreated,
 		Success: true,
@@ -188,7 +230,8 @@ func (s *userService) UpdateByID(ctx context.Context, input *model.UserUpdateInp
 	logData := helpers.CreateLog(s)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
-	if user, err := s.repository.FindByID(ctx, id); user == nil || err != nil {
+	user, err := s.repository.FindByID(ctx, id)
+	if user == nil || err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusNotFound,
 			Success: false,
@@ -217,7 +260,7 @@ func (s *userService) UpdateByID(ctx context.Context, input *model.UserUpdateInp
 		})
 	}
 
-	if err := s.repository.Update(ctx, userEntity); err != nil {
+	if err := s.repository.UpdateWithRoles(ctx, userEntity); err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
 			Success: false,
@@ -226,6 +269,16 @@ func (s *userService) UpdateByID(ctx context.Context, input *model.UserUpdateInp
 		})
 	}
 
+	if err := s.cacheRedis.Del(ctx, fmt.Sprintf("cache:user-detail:user-id:%d", id)); err != nil {
+		log.Println(err)
+	}
+
+	// Changing assigned roles change the permissions of issued access token
+	if user.RoleID != userEntity.RoleID || !sameRoleIDs(user.RoleIDs(), userEntity.RoleIDs()) {
+		s.tokenVersionService.BumpUser(ctx, id)
+		s.tokenDenyListService.DenyUser(ctx, id)
+	}
+
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 		Status:  fiber.StatusOK,
 		Success: true,
@@ -237,7 +290,8 @@ func (s *userService) ChangePassByID(ctx context.Context, input *model.ChangePas
 	logData := helpers.CreateLog(s)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
 
-	if user, err := s.repository.FindByID(ctx, id); user == nil || err != nil {
+	user, err := s.repository.FindByID(ctx, id)
+	if user == nil || err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusNotFound,
 			Success: false,
@@ -246,6 +300,15 @@ func (s *userService) ChangePassByID(ctx context.Context, input *model.ChangePas
 		})
 	}
 
+	if errs := s.passwordPolicyService.Validate(ctx, user, input.Password); len(errs) > 0 {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  errs,
+		})
+	}
+
 	userEntity := input.ToEntity()
 	if userEntity == nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
@@ -256,6 +319,7 @@ func (s *userService) ChangePassByID(ctx context.Context, input *model.ChangePas
 	}
 
 	userEntity.ID = id
+	userEntity.PasswordChangedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	if err := s.repository.Update(ctx, userEntity); err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
@@ -265,6 +329,17 @@ func (s *userService) ChangePassByID(ctx context.Context, input *model.ChangePas
 		})
 	}
 
+	s.passwordPolicyService.Record(ctx, id, userEntity.Password)
+
+	if err := s.revokeAllSessions(ctx, id); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error revoking session",
+			Errors:  err,
+		})
+	}
+
 	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 		Status:  fiber.StatusOK,
 		Success: true,
@@ -286,6 +361,7 @@ func (s *userService) DeleteByID(ctx context.Context, id uint) helpers.BaseRespo
 		})
 	}
 
+	s.tokenDenyListService.DenyUser(ctx, id)
 	if err := s.repository.Delete(ctx, user); err != nil {
 		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
 			Status:  fiber.StatusInternalServerError,
@@ -302,6 +378,275 @@ func (s *userService) DeleteByID(ctx context.Context, id uint) helpers.BaseRespo
 	})
 }
 
+// UnlockByID lift login lockout and clear failed attempt of user
+func (s *userService) UnlockByID(ctx context.Context, id uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, err := s.repository.FindByID(ctx, id)
+	if err != nil || user == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	s.loginThrottleService.Unlock(ctx, user.ID)
+
+	adminID, _ := ctx.Value(constant.CtxKeyUserID).(float64)
+	helpers.LogSecurityEvent(ctx, "Account unlocked by admin", map[string]interface{}{
+		"user_id":  user.ID,
+		"admin_id": uint(adminID),
+	})
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "User successfully unlocked",
+	})
+}
+
+// ApproveByID let self registered user waiting for approval login
+func (s *userService) ApproveByID(ctx context.Context, id uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, err := s.repository.FindByID(ctx, id)
+	if err != nil || user == nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	if !user.PendingApproval {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusOK,
+			Success: true,
+			Message: "User already approved",
+		})
+	}
+
+	if err := s.repository.Approve(ctx, id); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+			Errors:  err,
+		})
+	}
+
+	if err := s.cacheRedis.Del(ctx, fmt.Sprintf("cache:user-detail:user-id:%d", id)); err != nil {
+		log.Println(err)
+	}
+
+	adminID, _ := ctx.Value(constant.CtxKeyUserID).(float64)
+	helpers.LogSecurityEvent(ctx, "User registration approved by admin", map[string]interface{}{
+		"user_id":  user.ID,
+		"admin_id": uint(adminID),
+	})
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "User successfully approved",
+	})
+}
+
+// UpdateProfileByID let user change their own username and email, changed email need to be
+// verified again before validated only endpoint could be used
+func (s *userService) UpdateProfileByID(ctx context.Context, input *model.ProfileUpdateInput, id uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, err := s.repository.FindByID(ctx, id)
+	if user == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	userEntity := input.ToEntity()
+	userEntity.ID = id
+	userEntity.RoleID = user.RoleID
+
+	if err := s.ValidateEntityInput(ctx, userEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+		})
+	}
+
+	if err := s.repository.Update(ctx, userEntity); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+			Errors:  err,
+		})
+	}
+
+	if userEntity.Email != user.Email {
+		if err := s.repository.ResetValidation(ctx, id); err != nil {
+			return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusInternalServerError,
+				Success: false,
+				Message: "Error updating data",
+				Errors:  err,
+			})
+		}
+
+		// Token still claim the old email and validation, user need to login again
+		s.tokenDenyListService.DenyUser(ctx, id)
+
+		if err := sendVerificationEmail(ctx, s.mailClient, userEntity); err != nil {
+			log.Printf("failed sending verification email to user %d: %v", id, err)
+		}
+	}
+
+	if err := s.cacheRedis.Del(ctx, fmt.Sprintf("cache:user-detail:user-id:%d", id)); err != nil {
+		log.Println(err)
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Profile successfully updated",
+	})
+}
+
+// ChangeOwnPasswordByID let user change their own password after proving the current one,
+// wrong current password count as failed login so it could not be guessed through here
+func (s *userService) ChangeOwnPasswordByID(ctx context.Context, input *model.ChangeOwnPasswordInput, id uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, err := s.repository.FindByID(ctx, id)
+	if user == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	ipAddress, _ := ctx.Value(constant.CtxKeyIPAddress).(string)
+	account := LoginAccount(user, "")
+	if retryAfter, _ := s.loginThrottleService.Check(ctx, account, ipAddress); retryAfter > 0 {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusTooManyRequests,
+			Success: false,
+			Message: "Too many failed attempts, please try again later",
+			Data:    &model.LoginThrottle{RetryAfter: int(math.Ceil(retryAfter.Seconds()))},
+		})
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.OldPassword)); err != nil {
+		s.loginThrottleService.RecordFailure(ctx, account, ipAddress, user.ID)
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors: []helpers.ValidationError{{
+				Field: "old_password",
+				Tag:   "mismatch",
+			}},
+		})
+	}
+
+	s.loginThrottleService.Reset(ctx, account)
+
+	if errs := s.passwordPolicyService.Validate(ctx, user, input.Password); len(errs) > 0 {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  errs,
+		})
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+			Errors:  err,
+		})
+	}
+
+	if err := s.repository.Update(ctx, &entity.User{
+		ID:                id,
+		Password:          string(hashedPassword),
+		PasswordChangedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error updating data",
+			Errors:  err,
+		})
+	}
+
+	s.passwordPolicyService.Record(ctx, id, string(hashedPassword))
+
+	if err := s.revokeAllSessions(ctx, id); err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusInternalServerError,
+			Success: false,
+			Message: "Error revoking session",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "Password successfully changed, please login again",
+	})
+}
+
+// revokeAllSessions sign user out of every device, access token is denied and refresh token
+// revoked so none of them could mint new access token after the password changed
+func (s *userService) revokeAllSessions(ctx context.Context, id uint) error {
+	s.tokenDenyListService.DenyUser(ctx, id)
+
+	return s.refreshTokenRepository.RevokeAllByUserID(ctx, id)
+}
+
+// GetPermissionsByID return role and effective permissions of user
+func (s *userService) GetPermissionsByID(ctx context.Context, id uint) helpers.BaseResponse {
+	logData := helpers.CreateLog(s)
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	user, err := s.repository.FindByIDWithRole(ctx, id)
+	if user == nil || err != nil {
+		return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusNotFound,
+			Success: false,
+			Message: "User not found",
+			Errors:  err,
+		})
+	}
+
+	return helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+		Status:  fiber.StatusOK,
+		Success: true,
+		Message: "User permissions found",
+		Data:    model.UserToPermissionsModel(user),
+	})
+}
+
 func (s *userService) ValidateEntityInput(ctx context.Context, user *entity.User) interface{} {
 	logData := helpers.CreateLog(s)
 	defer helpers.LogSystemWithDefer(ctx, &logData)
@@ -315,6 +660,13 @@ func (s *userService) ValidateEntityInput(ctx context.Context, user *entity.User
 		})
 	}
 
+	if roles, err := s.roleRepository.FindByIDs(ctx, user.RoleIDs()); roles == nil || err != nil || len(*roles) != len(user.Roles) {
+		errors = append(errors, helpers.ValidationError{
+			Field: "role_ids",
+			Tag:   "not_found",
+		})
+	}
+
 	if exist := s.repository.UsernameExist(ctx, user); exist {
 		errors = append(errors, helpers.ValidationError{
 			Field: "name",
@@ -343,3 +695,23 @@ func (s *userService) ValidateEntityInput(ctx context.Context, user *entity.User
 	}
 	return nil
 }
+
+// sameRoleIDs report whether both hold the same set of role id regardless of order
+func sameRoleIDs(a []uint, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	set := make(map[uint]struct{}, len(a))
+	for _, id := range a {
+		set[id] = struct{}{}
+	}
+
+	for _, id := range b {
+		if _, exists := set[id]; !exists {
+			return false
+		}
+	}
+
+	return true
+}
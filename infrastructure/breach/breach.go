@@ -0,0 +1,170 @@
+package breach
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+)
+
+const (
+	prefixLength = 5
+	hashLength   = sha1.Size * 2
+)
+
+// Lookup tell whether password appear in a corpus of breached password, implementation
+// other than the local file (e.g. internal service, bloom filter) could be plugged in here
+type Lookup interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
+
+// NewLookup open breached password list configured by BREACHED_PASSWORD_PATH, nil is returned
+// when no list configured so the check is skipped. Path could either be directory of HIBP
+// k-anonymity bucket (file named by the first 5 hex of SHA-1, holding "SUFFIX:COUNT" per line)
+// read on demand, or single file of "SHA1:COUNT" per line loaded into memory at startup
+func NewLookup(cfg *config.Config) (Lookup, error) {
+	if cfg.BreachedPasswordPath == "" {
+		return nil, nil
+	}
+
+	info, err := os.Stat(cfg.BreachedPasswordPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return &bucketLookup{
+			dir:      cfg.BreachedPasswordPath,
+			minCount: cfg.BreachedPasswordMinCount,
+		}, nil
+	}
+
+	list, err := loadHashList(cfg.BreachedPasswordPath, cfg.BreachedPasswordMinCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// bucketLookup read only the bucket sharing the password hash prefix, so the full corpus
+// never need to fit in memory
+type bucketLookup struct {
+	dir      string
+	minCount int
+}
+
+func (l *bucketLookup) IsBreached(ctx context.Context, password string) (bool, error) {
+	hash := hashPassword(password)
+	prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+
+	file, err := l.openBucket(prefix)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Bucket without entry is not always written by downloader
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineSuffix, count, ok := parseLine(scanner.Text())
+		if ok && lineSuffix == suffix {
+			return count >= l.minCount, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+func (l *bucketLookup) openBucket(prefix string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(l.dir, prefix+".txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.Open(filepath.Join(l.dir, prefix))
+	}
+
+	return file, err
+}
+
+// hashList hold the whole list in memory, meant for curated list such as top common password
+type hashList struct {
+	hashes map[[sha1.Size]byte]struct{}
+}
+
+func loadHashList(path string, minCount int) (*hashList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := &hashList{hashes: map[[sha1.Size]byte]struct{}{}}
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		hash, count, ok := parseLine(scanner.Text())
+		if !ok || len(hash) != hashLength {
+			return nil, fmt.Errorf("invalid breached password entry at line %d", lineNumber)
+		}
+
+		if count < minCount {
+			continue
+		}
+
+		var key [sha1.Size]byte
+		if _, err := hex.Decode(key[:], []byte(hash)); err != nil {
+			return nil, fmt.Errorf("invalid breached password entry at line %d", lineNumber)
+		}
+		list.hashes[key] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (l *hashList) IsBreached(ctx context.Context, password string) (bool, error) {
+	_, found := l.hashes[sha1.Sum([]byte(password))]
+	return found, nil
+}
+
+// parseLine split "HASH:COUNT" entry, count is optional and treated as 1 when missing
+func parseLine(line string) (string, int, bool) {
+	hash, countText, hasCount := strings.Cut(strings.TrimSpace(line), ":")
+	if hash == "" {
+		return "", 0, false
+	}
+
+	count := 1
+	if hasCount {
+		parsed, err := strconv.Atoi(countText)
+		if err != nil {
+			return "", 0, false
+		}
+		count = parsed
+	}
+
+	return strings.ToUpper(hash), count, true
+}
+
+func hashPassword(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
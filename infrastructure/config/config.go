@@ -24,6 +24,66 @@ type Config struct {
 	JwtAccessTime           int    `mapstructure:"JWT_ACCESS_TIME"`
 	JwtRefreshTime          int    `mapstructure:"JWT_REFRESH_TIME"`
 
+	// JWT key ring, comma separated "kid:base64_pem" used for rotation alongside the secret above
+	JwtAccessKeys        string `mapstructure:"JWT_ACCESS_KEYS"`
+	JwtAccessSigningKID  string `mapstructure:"JWT_ACCESS_SIGNING_KID"`
+	JwtRefreshKeys       string `mapstructure:"JWT_REFRESH_KEYS"`
+	JwtRefreshSigningKID string `mapstructure:"JWT_REFRESH_SIGNING_KID"`
+
+	// Key ring of challenge token (two-factor, password change, email verification), never
+	// published so it could not pass as access token, refresh key ring is used when empty
+	JwtChallengeKeys       string `mapstructure:"JWT_CHALLENGE_KEYS"`
+	JwtChallengeSigningKID string `mapstructure:"JWT_CHALLENGE_SIGNING_KID"`
+
+	// Permission mode, "claim" embed permissions in access token while "runtime"
+	// only embed role and resolve permissions on every request
+	PermissionMode string `mapstructure:"PERMISSION_MODE"`
+
+	// Admin impersonation, in minutes
+	ImpersonationTime int `mapstructure:"IMPERSONATION_TIME"`
+
+	// Time-bound grant, in seconds between grant job run
+	GrantJobInterval int `mapstructure:"GRANT_JOB_INTERVAL"`
+
+	// OAuth2 client credentials
+	OAuthClientTokenTime int `mapstructure:"OAUTH_CLIENT_TOKEN_TIME"`
+
+	// OpenID Connect, comma separated provider name, each configured by OIDC_<NAME>_* (see oidc.go)
+	OidcProviders string `mapstructure:"OIDC_PROVIDERS"`
+	OidcStateTime int    `mapstructure:"OIDC_STATE_TIME"`
+
+	// Login brute force protection
+	LoginMaxAttempt    int `mapstructure:"LOGIN_MAX_ATTEMPT"`
+	LoginMaxAttemptIP  int `mapstructure:"LOGIN_MAX_ATTEMPT_IP"`
+	LoginAttemptWindow int `mapstructure:"LOGIN_ATTEMPT_WINDOW"`
+	LoginLockoutTime   int `mapstructure:"LOGIN_LOCKOUT_TIME"`
+	LoginDelayMax      int `mapstructure:"LOGIN_DELAY_MAX"`
+
+	// Self registration, role is looked up by name
+	RegistrationEnabled         bool   `mapstructure:"REGISTRATION_ENABLED"`
+	RegistrationDefaultRole     string `mapstructure:"REGISTRATION_DEFAULT_ROLE"`
+	RegistrationRequireApproval bool   `mapstructure:"REGISTRATION_REQUIRE_APPROVAL"`
+
+	// Password policy
+	PasswordMinLength        int  `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMaxLength        int  `mapstructure:"PASSWORD_MAX_LENGTH"`
+	PasswordRequireUpper     bool `mapstructure:"PASSWORD_REQUIRE_UPPER"`
+	PasswordRequireLower     bool `mapstructure:"PASSWORD_REQUIRE_LOWER"`
+	PasswordRequireDigit     bool `mapstructure:"PASSWORD_REQUIRE_DIGIT"`
+	PasswordRequireSymbol    bool `mapstructure:"PASSWORD_REQUIRE_SYMBOL"`
+	PasswordDisallowIdentity bool `mapstructure:"PASSWORD_DISALLOW_IDENTITY"`
+	PasswordHistory          int  `mapstructure:"PASSWORD_HISTORY"`
+	PasswordMaxAge           int  `mapstructure:"PASSWORD_MAX_AGE"`
+	PasswordChangeTime       int  `mapstructure:"PASSWORD_CHANGE_TIME"`
+
+	// Breached password list, either HIBP bucket directory or single hash file (see breach.go)
+	BreachedPasswordPath     string `mapstructure:"BREACHED_PASSWORD_PATH"`
+	BreachedPasswordMinCount int    `mapstructure:"BREACHED_PASSWORD_MIN_COUNT"`
+
+	// Two Factor
+	TwoFactorIssuer        string `mapstructure:"TWO_FACTOR_ISSUER"`
+	TwoFactorChallengeTime int    `mapstructure:"TWO_FACTOR_CHALLENGE_TIME"`
+
 	// Cors
 	CorsMaxAge       int    `mapstructure:"CORS_MAX_AGE"`
 	CorsAllowOrigins string `mapstructure:"CORS_ALLOW_ORIGINS"`
@@ -45,6 +105,14 @@ type Config struct {
 	SmtpHost     string `mapstructure:"SMTP_HOST"`
 	SmtPort      string `mapstructure:"SMTP_PORT"`
 
+	// Email Verification
+	EmailVerificationURL  string `mapstructure:"EMAIL_VERIFICATION_URL"`
+	EmailVerificationTime int    `mapstructure:"EMAIL_VERIFICATION_TIME"`
+
+	// Password Reset
+	PasswordResetURL  string `mapstructure:"PASSWORD_RESET_URL"`
+	PasswordResetTime int    `mapstructure:"PASSWORD_RESET_TIME"`
+
 	// Redis
 	RedisAddress  string `mapstructure:"REDIS_ADDRESS"`
 	RedisPassword string `mapstructure:"REDIS_PASSWORD"`
@@ -82,6 +150,34 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("PORT", "4000")
 	viper.SetDefault("JWT_ACCESS_TIME", 30)
 	viper.SetDefault("JWT_REFRESH_TIME", 168)
+	viper.SetDefault("PERMISSION_MODE", "claim")
+	viper.SetDefault("IMPERSONATION_TIME", 15)
+	viper.SetDefault("GRANT_JOB_INTERVAL", 60)
+	viper.SetDefault("OAUTH_CLIENT_TOKEN_TIME", 15)
+	viper.SetDefault("OIDC_STATE_TIME", 10)
+	viper.SetDefault("LOGIN_MAX_ATTEMPT", 5)
+	viper.SetDefault("LOGIN_MAX_ATTEMPT_IP", 20)
+	viper.SetDefault("LOGIN_ATTEMPT_WINDOW", 15)
+	viper.SetDefault("LOGIN_LOCKOUT_TIME", 15)
+	viper.SetDefault("LOGIN_DELAY_MAX", 30)
+	viper.SetDefault("REGISTRATION_ENABLED", false)
+	viper.SetDefault("REGISTRATION_DEFAULT_ROLE", "User")
+	viper.SetDefault("REGISTRATION_REQUIRE_APPROVAL", false)
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 8)
+	viper.SetDefault("PASSWORD_MAX_LENGTH", 72)
+	viper.SetDefault("PASSWORD_REQUIRE_UPPER", true)
+	viper.SetDefault("PASSWORD_REQUIRE_LOWER", true)
+	viper.SetDefault("PASSWORD_REQUIRE_DIGIT", true)
+	viper.SetDefault("PASSWORD_REQUIRE_SYMBOL", false)
+	viper.SetDefault("PASSWORD_DISALLOW_IDENTITY", true)
+	viper.SetDefault("PASSWORD_HISTORY", 5)
+	viper.SetDefault("PASSWORD_MAX_AGE", 0)
+	viper.SetDefault("PASSWORD_CHANGE_TIME", 10)
+	viper.SetDefault("BREACHED_PASSWORD_MIN_COUNT", 1)
+	viper.SetDefault("TWO_FACTOR_CHALLENGE_TIME", 5)
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("EMAIL_VERIFICATION_TIME", 1440)
+	viper.SetDefault("PASSWORD_RESET_TIME", 30)
 
 	// Try to read the configuration file (optional)
 	if err := viper.ReadInConfig(); err != nil {
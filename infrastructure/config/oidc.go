@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// OidcProvider is configuration of single OpenID Connect provider, read from OIDC_<NAME>_* so
+// number of provider is not fixed by Config struct
+type OidcProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+	DefaultRole  string
+
+	// RoleMapping keep config order, so the first group matched decide the role
+	RoleMapping []OidcRoleMapping
+}
+
+type OidcRoleMapping struct {
+	Group string
+	Role  string
+}
+
+// GetOidcProvider return configuration of provider listed in OIDC_PROVIDERS
+func GetOidcProvider(name string) (*OidcProvider, bool) {
+	enabled := false
+	for _, provider := range strings.Split(AppConfig.OidcProviders, ",") {
+		if strings.EqualFold(strings.TrimSpace(provider), name) {
+			enabled = true
+			break
+		}
+	}
+
+	if !enabled || name == "" {
+		return nil, false
+	}
+
+	get := func(key string) string {
+		return strings.TrimSpace(viper.GetString(fmt.Sprintf("OIDC_%s_%s", strings.ToUpper(name), key)))
+	}
+
+	provider := &OidcProvider{
+		Name:         strings.ToLower(name),
+		Issuer:       strings.TrimSuffix(get("ISSUER"), "/"),
+		ClientID:     get("CLIENT_ID"),
+		ClientSecret: get("CLIENT_SECRET"),
+		RedirectURL:  get("REDIRECT_URL"),
+		Scopes:       strings.Fields(get("SCOPES")),
+		GroupsClaim:  get("GROUPS_CLAIM"),
+		DefaultRole:  get("DEFAULT_ROLE"),
+	}
+
+	if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+		return nil, false
+	}
+
+	if len(provider.Scopes) == 0 {
+		provider.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	if provider.GroupsClaim == "" {
+		provider.GroupsClaim = "groups"
+	}
+
+	for _, entry := range strings.Split(get("ROLE_MAPPING"), ",") {
+		group, role, found := strings.Cut(entry, ":")
+		if !found || strings.TrimSpace(group) == "" || strings.TrimSpace(role) == "" {
+			continue
+		}
+
+		provider.RoleMapping = append(provider.RoleMapping, OidcRoleMapping{
+			Group: strings.TrimSpace(group),
+			Role:  strings.TrimSpace(role),
+		})
+	}
+
+	return provider, true
+}
@@ -1,7 +1,10 @@
 package database
 
 import (
+	"log"
+
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
 	"gorm.io/gorm"
 )
 
@@ -9,7 +12,90 @@ func Migrate(db *gorm.DB) {
 	db.AutoMigrate(&entity.Module{})
 	db.AutoMigrate(&entity.RolePermission{})
 	db.AutoMigrate(&entity.Permission{})
+	db.AutoMigrate(&entity.PermissionImplication{})
+	migratePermissionImplications(db)
 	db.AutoMigrate(&entity.Role{})
+	db.AutoMigrate(&entity.RoleParent{})
 	db.AutoMigrate(&entity.User{})
+	db.AutoMigrate(&entity.UserRole{})
+	migrateUserRoles(db)
 	db.AutoMigrate(&entity.RefreshToken{})
+	migrateRefreshTokenHash(db)
+	db.AutoMigrate(&entity.UserTwoFactor{})
+	db.AutoMigrate(&entity.TwoFactorRecoveryCode{})
+	db.AutoMigrate(&entity.PasswordReset{})
+	db.AutoMigrate(&entity.ApiKey{})
+	db.AutoMigrate(&entity.Client{})
+	db.AutoMigrate(&entity.PasswordHistory{})
+	db.AutoMigrate(&entity.UserGrant{})
+	db.AutoMigrate(&entity.UserPermission{})
+	db.AutoMigrate(&entity.UserIdentity{})
+}
+
+// migrateUserRoles assign primary role of every user into user roles, so user created before
+// multiple role supported keep its role, existing assignment is left untouched
+func migrateUserRoles(db *gorm.DB) {
+	if err := db.Exec(
+		"INSERT IGNORE INTO " + constant.TABLE_USER_ROLE + " (user_id, role_id) " +
+			"SELECT id, role_id FROM " + constant.TABLE_USER + " WHERE role_id <> 0",
+	).Error; err != nil {
+		log.Printf("failed assigning primary role into user roles: %v", err)
+	}
+}
+
+// migratePermissionImplications let create, update and delete permission imply view permission of
+// the same subject in the same module for database created before implication supported, run only
+// while no implication exist yet so implication removed later by admin is not brought back
+func migratePermissionImplications(db *gorm.DB) {
+	var totalImplication int64
+	if err := db.Model(&entity.PermissionImplication{}).Count(&totalImplication).Error; err != nil || totalImplication > 0 {
+		return
+	}
+
+	if err := db.Exec(
+		"INSERT IGNORE INTO " + constant.TABLE_PERMISSION_IMPLICATION + " (permission_id, implied_id) " +
+			"SELECT p.id, v.id FROM " + constant.TABLE_PERMISSION + " p JOIN " + constant.TABLE_PERMISSION + " v " +
+			"ON v.module_id = p.module_id AND v.name = CONCAT('View ', SUBSTRING(p.name, 8)) " +
+			"WHERE (p.name LIKE 'Create %' OR p.name LIKE 'Update %' OR p.name LIKE 'Delete %') " +
+			"AND p.deleted_at IS NULL AND v.deleted_at IS NULL",
+	).Error; err != nil {
+		log.Printf("failed assigning permission implications: %v", err)
+	}
+}
+
+// migrateRefreshTokenHash convert refresh token stored in plaintext into SHA-256 digest
+// then drop the plaintext column, run only once while the legacy column still exist
+func migrateRefreshTokenHash(db *gorm.DB) {
+	if !db.Migrator().HasColumn(&entity.RefreshToken{}, "token") {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Legacy token had no jti, so refresh in the same second produced the same token string.
+		// Only one of them is kept, otherwise its digest would break the unique index
+		if err := tx.Exec(
+			"DELETE t1 FROM " + constant.TABLE_REFRESH_TOKEN + " t1 JOIN " + constant.TABLE_REFRESH_TOKEN + " t2 " +
+				"ON t1.token = t2.token AND t1.uuid < t2.uuid " +
+				"WHERE t1.token_hash IS NULL AND t2.token_hash IS NULL",
+		).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&entity.RefreshToken{}).
+			Where("token_hash IS NULL AND token IS NOT NULL").
+			Update("token_hash", gorm.Expr("SHA2(token, 256)")).Error; err != nil {
+			return err
+		}
+
+		// Row without token could never be matched again
+		return tx.Where("token_hash IS NULL").Delete(&entity.RefreshToken{}).Error
+	})
+	if err != nil {
+		log.Printf("failed converting refresh token into hash: %v", err)
+		return
+	}
+
+	if err := db.Migrator().DropColumn(&entity.RefreshToken{}, "token"); err != nil {
+		log.Printf("failed dropping plaintext refresh token column: %v", err)
+	}
 }
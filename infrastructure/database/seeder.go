@@ -3,8 +3,10 @@ package database
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"log"
 	"os/user"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -43,6 +45,19 @@ func Seeding(db *gorm.DB) {
 		}
 	}
 
+	{ // Seeding permission implication
+		var totalImplication int64
+		tx.Model(&entity.PermissionImplication{}).Count(&totalImplication)
+		if totalImplication == 0 {
+			if err := seedingPermissionImplication(tx); err != nil {
+				log.Printf("Seeding permission implication failed: %v", err)
+				return
+			}
+
+			log.Println("Success seeding permission implication")
+		}
+	}
+
 	{ // Seeding role admin
 		var totalRoleAdmin int64
 		tx.Model(&entity.Role{}).Where("name = ?", "Admin").Count(&totalRoleAdmin)
@@ -385,6 +400,45 @@ func seedingPermissionUserManagement(tx *gorm.DB) error {
 	return nil
 }
 
+// seedingPermissionImplication let create, update and delete permission imply view permission
+// of the same subject in the same module (e.g. "Update User" implies "View User")
+func seedingPermissionImplication(tx *gorm.DB) error {
+	var permissions []entity.Permission
+	if err := tx.Model(&entity.Permission{}).Find(&permissions).Error; err != nil {
+		return err
+	}
+
+	views := map[string]uint{}
+	for _, permission := range permissions {
+		if subject, found := strings.CutPrefix(permission.Name, "View "); found {
+			views[fmt.Sprintf("%d:%s", permission.ModuleID, subject)] = permission.ID
+		}
+	}
+
+	implications := []entity.PermissionImplication{}
+	for _, permission := range permissions {
+		for _, action := range []string{"Create ", "Update ", "Delete "} {
+			subject, found := strings.CutPrefix(permission.Name, action)
+			if !found {
+				continue
+			}
+
+			if viewID, exists := views[fmt.Sprintf("%d:%s", permission.ModuleID, subject)]; exists {
+				implications = append(implications, entity.PermissionImplication{
+					PermissionID: permission.ID,
+					ImpliedID:    viewID,
+				})
+			}
+		}
+	}
+
+	if len(implications) == 0 {
+		return nil
+	}
+
+	return tx.Create(&implications).Error
+}
+
 func seedingRoleAdmin(tx *gorm.DB) error {
 	adminUUID, err := uuid.Parse("1254f6bf-8a3d-46de-a89d-ed901f90a7ad")
 	if err != nil {
@@ -455,6 +509,7 @@ func seedingUserAdmin(tx *gorm.DB) error {
 	user := entity.User{
 		UUID:        adminUUID,
 		RoleID:      adminRole.ID,
+		Roles:       []entity.Role{adminRole},
 		Username:    "admin",
 		Email:       "admin@email.id",
 		Password:    cfg.AdminPass,
@@ -484,6 +539,7 @@ func seedingUserGuest(tx *gorm.DB) error {
 	user := entity.User{
 		UUID:        userUUID,
 		RoleID:      userRole.ID,
+		Roles:       []entity.Role{userRole},
 		Username:    "user",
 		Email:       "user@email.id",
 		Password:    "1234567",
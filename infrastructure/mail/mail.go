@@ -0,0 +1,116 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+)
+
+type MailClient struct {
+	host     string
+	port     string
+	from     string
+	password string
+	fromName string
+}
+
+func NewMailClient(cfg *config.Config) *MailClient {
+	return &MailClient{
+		host:     cfg.SmtpHost,
+		port:     cfg.SmtPort,
+		from:     cfg.SmtpEmail,
+		password: cfg.SmtpPassword,
+		fromName: cfg.AppName,
+	}
+}
+
+// Send deliver html mail through configured SMTP server.
+//
+// STARTTLS and AUTH only used when server advertise it, so local SMTP stand-in (e.g. mailpit, mailhog)
+// could be used without credential
+func (m *MailClient) Send(ctx context.Context, to string, subject string, body string) error {
+	if m.host == "" {
+		return fmt.Errorf("smtp host is not configured")
+	}
+
+	address := net.JoinHostPort(m.host, m.port)
+
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("could not connect smtp server: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("could not create smtp client: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return fmt.Errorf("could not start tls: %w", err)
+		}
+	}
+
+	if ok, _ := client.Extension("AUTH"); ok && m.password != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.from, m.password, m.host)); err != nil {
+			return fmt.Errorf("could not authenticate smtp: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.from); err != nil {
+		return err
+	}
+
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(m.buildMessage(to, subject, body)); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (m *MailClient) buildMessage(to string, subject string, body string) []byte {
+	var sb strings.Builder
+
+	from := m.from
+	if m.fromName != "" {
+		from = fmt.Sprintf("%s <%s>", mime.QEncoding.Encode("utf-8", m.fromName), m.from)
+	}
+
+	sb.WriteString(fmt.Sprintf("From: %s\r\n", from))
+	sb.WriteString(fmt.Sprintf("To: %s\r\n", to))
+	sb.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject)))
+	sb.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().Format(time.RFC1123Z)))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/html; charset=\"utf-8\"\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(body)
+
+	return []byte(sb.String())
+}
@@ -0,0 +1,379 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+)
+
+const (
+	discoveryTTL    = time.Hour
+	keySetTTL       = time.Hour
+	keySetMinReload = time.Minute
+)
+
+// Client talk to OpenID Connect provider, discovery document and signing key of each issuer
+// are cached so callback does not fetch them on every login
+type Client struct {
+	httpClient *http.Client
+
+	mutex   sync.Mutex
+	issuers map[string]*issuer
+}
+
+type issuer struct {
+	discovery   *Discovery
+	discoveryAt time.Time
+	keys        map[string]interface{}
+	keysAt      time.Time
+}
+
+type (
+	Discovery struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		JwksURI               string `json:"jwks_uri"`
+	}
+
+	// IDToken hold verified claim used to link or provision user
+	IDToken struct {
+		Subject           string
+		Email             string
+		EmailVerified     *bool
+		Name              string
+		PreferredUsername string
+		Groups            []string
+	}
+
+	jsonWebKey struct {
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		Kid string `json:"kid"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+
+	tokenResponse struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+)
+
+func NewClient() *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		issuers:    map[string]*issuer{},
+	}
+}
+
+// CodeChallenge derive PKCE S256 challenge from code verifier (RFC 7636 section 4.2)
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL build authorization request URL the user agent is redirected to
+func (c *Client) AuthCodeURL(ctx context.Context, provider *config.OidcProvider, state, nonce, codeVerifier string) (string, error) {
+	discovery, err := c.discover(ctx, provider.Issuer)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", provider.ClientID)
+	query.Set("redirect_uri", provider.RedirectURL)
+	query.Set("scope", strings.Join(provider.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange trade authorization code for token at provider token endpoint and return raw ID token
+func (c *Client) Exchange(ctx context.Context, provider *config.OidcProvider, code, codeVerifier string) (string, error) {
+	discovery, err := c.discover(ctx, provider.Issuer)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", provider.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", provider.ClientID)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	// Confidential client authenticate with client_secret_basic, public client rely on PKCE only
+	if provider.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(provider.ClientID), url.QueryEscape(provider.ClientSecret))
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("token request: %w", err)
+	}
+	defer response.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(&token); err != nil {
+		return "", fmt.Errorf("decode token response: %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request rejected: %s %s", token.Error, token.ErrorDescription)
+	}
+
+	if token.IDToken == "" {
+		return "", fmt.Errorf("token response has no id_token")
+	}
+
+	return token.IDToken, nil
+}
+
+// VerifyIDToken check signature, issuer, audience, expiry and nonce of ID token (OIDC Core section 3.1.3.7)
+func (c *Client) VerifyIDToken(ctx context.Context, provider *config.OidcProvider, rawIDToken, nonce string) (*IDToken, error) {
+	discovery, err := c.discover(ctx, provider.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	claim := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claim, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.key(ctx, provider.Issuer, discovery.JwksURI, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(provider.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("verify id token: %w", err)
+	}
+
+	if claimNonce, _ := claim["nonce"].(string); claimNonce != nonce {
+		return nil, fmt.Errorf("verify id token: nonce mismatch")
+	}
+
+	// Token issued for several audience must name this client as authorized party
+	if audience, _ := claim.GetAudience(); len(audience) > 1 {
+		if azp, _ := claim["azp"].(string); azp != provider.ClientID {
+			return nil, fmt.Errorf("verify id token: authorized party mismatch")
+		}
+	}
+
+	idToken := &IDToken{Groups: []string{}}
+	idToken.Subject, _ = claim["sub"].(string)
+	idToken.Email, _ = claim["email"].(string)
+	idToken.Name, _ = claim["name"].(string)
+	idToken.PreferredUsername, _ = claim["preferred_username"].(string)
+
+	if verified, ok := claim["email_verified"].(bool); ok {
+		idToken.EmailVerified = &verified
+	}
+
+	// Group claim is usually array but some provider send single string
+	switch groups := claim[provider.GroupsClaim].(type) {
+	case []interface{}:
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				idToken.Groups = append(idToken.Groups, name)
+			}
+		}
+	case string:
+		idToken.Groups = append(idToken.Groups, groups)
+	}
+
+	if idToken.Subject == "" {
+		return nil, fmt.Errorf("verify id token: missing subject")
+	}
+
+	return idToken, nil
+}
+
+func (c *Client) discover(ctx context.Context, issuerURL string) (*Discovery, error) {
+	c.mutex.Lock()
+	cached, ok := c.issuers[issuerURL]
+	if ok && cached.discovery != nil && time.Since(cached.discoveryAt) < discoveryTTL {
+		c.mutex.Unlock()
+		return cached.discovery, nil
+	}
+	c.mutex.Unlock()
+
+	var discovery Discovery
+	if err := c.getJSON(ctx, issuerURL+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuerURL {
+		return nil, fmt.Errorf("discovery: issuer mismatch %s", discovery.Issuer)
+	}
+
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksURI == "" {
+		return nil, fmt.Errorf("discovery: incomplete provider metadata")
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.issuers[issuerURL]; !ok {
+		c.issuers[issuerURL] = &issuer{}
+	}
+	c.issuers[issuerURL].discovery = &discovery
+	c.issuers[issuerURL].discoveryAt = time.Now()
+
+	return &discovery, nil
+}
+
+// key return verification key by kid, key set is reloaded when expired or kid is unknown
+// (provider rotated key) but not more than once per minute
+func (c *Client) key(ctx context.Context, issuerURL, jwksURI, kid string) (interface{}, error) {
+	c.mutex.Lock()
+	cached := c.issuers[issuerURL]
+	if cached != nil && cached.keys != nil {
+		key, found := cached.keys[kid]
+		if found && time.Since(cached.keysAt) < keySetTTL {
+			c.mutex.Unlock()
+			return key, nil
+		}
+
+		if time.Since(cached.keysAt) < keySetMinReload {
+			c.mutex.Unlock()
+			if found {
+				return key, nil
+			}
+			return nil, fmt.Errorf("unknown key id: %s", kid)
+		}
+	}
+	c.mutex.Unlock()
+
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := c.getJSON(ctx, jwksURI, &keySet); err != nil {
+		return nil, fmt.Errorf("key set: %w", err)
+	}
+
+	keys := map[string]interface{}{}
+	for _, jwk := range keySet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+
+	c.mutex.Lock()
+	if _, ok := c.issuers[issuerURL]; !ok {
+		c.issuers[issuerURL] = &issuer{}
+	}
+	c.issuers[issuerURL].keys = keys
+	c.issuers[issuerURL].keysAt = time.Now()
+	c.mutex.Unlock()
+
+	key, found := keys[kid]
+	if !found {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+
+	return key, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, endpoint string, dest interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", response.StatusCode, endpoint)
+	}
+
+	return json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(dest)
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
@@ -41,6 +41,15 @@ func (c *CacheClient) GetObject(ctx context.Context, key string, dest interface{
 	return json.Unmarshal([]byte(data), dest)
 }
 
+// GetDelObject read and delete key in single command, so value could be consumed only once
+func (c *CacheClient) GetDelObject(ctx context.Context, key string, dest interface{}) error {
+	data, err := c.client.GetDel(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), dest)
+}
+
 func (c *CacheClient) Exist(ctx context.Context, key string) (exist bool, err error) {
 	data, err := c.client.Exists(ctx, key).Result()
 
@@ -71,8 +80,35 @@ func (c *CacheClient) Set(ctx context.Context, key string, value any, expiration
 	return c.client.Set(ctx, key, data, expiration).Err()
 }
 
-func (c *CacheClient) Del(ctx context.Context, key string) error {
-	return c.client.Del(ctx, key).Err()
+// SetNX store value only when key does not exist yet, report whether value was stored
+func (c *CacheClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, key, value, expiration).Result()
+}
+
+func (c *CacheClient) Del(ctx context.Context, keys ...string) error {
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// Incr increment counter and start its expiration on the first increment, so counter
+// act as fixed window that is not extended by later increment. Counter is created with its
+// expiration in the same transaction, so it never end up living forever
+func (c *CacheClient) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+
+	if _, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, key, 0, expiration)
+		incr = pipe.Incr(ctx, key)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+// TTL return remaining time to live of key, negative when key does not exist or has no expiration
+func (c *CacheClient) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.client.TTL(ctx, key).Result()
 }
 
 func (c *CacheClient) Shutdown() error {
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type ApiKeyHandler interface {
+	GetAllApiKey(c *fiber.Ctx) error
+	CreateApiKey(c *fiber.Ctx) error
+	RevokeApiKey(c *fiber.Ctx) error
+}
+
+type apiKeyHandler struct {
+	service service.ApiKeyService
+}
+
+func NewApiKeyHandler(service service.ApiKeyService) ApiKeyHandler {
+	return &apiKeyHandler{
+		service: service,
+	}
+}
+
+func (h *apiKeyHandler) GetAllApiKey(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	userID := c.Locals("user_id").(float64)
+
+	response := h.service.GetAllByUserID(ctx, uint(userID))
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *apiKeyHandler) CreateApiKey(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	userID := c.Locals("user_id").(float64)
+
+	var input model.ApiKeyInput
+	var response helpers.BaseResponse
+
+	if err := c.BodyParser(&input); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		input.Sanitize()
+
+		if err := helpers.ValidateInput(input); err != nil {
+			response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request body",
+				Log:     &logData,
+				Errors:  err,
+			})
+		} else {
+			response = h.service.Create(ctx, &input, uint(userID))
+			response.Log = &logData
+		}
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *apiKeyHandler) RevokeApiKey(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	userID := c.Locals("user_id").(float64)
+
+	var response helpers.BaseResponse
+	apiKeyID, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid UUID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.RevokeByUUID(ctx, uint(userID), apiKeyID)
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
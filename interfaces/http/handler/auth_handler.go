@@ -12,6 +12,12 @@ type AuthHandler interface {
 	Logout(c *fiber.Ctx) error
 	Refresh(c *fiber.Ctx) error
 	Verify(c *fiber.Ctx) error
+	VerifyEmail(c *fiber.Ctx) error
+	ResendVerification(c *fiber.Ctx) error
+	ForgotPassword(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+	ChangeExpiredPassword(c *fiber.Ctx) error
+	Register(c *fiber.Ctx) error
 }
 
 type authHandler struct {
@@ -47,7 +53,7 @@ func (h *authHandler) Login(c *fiber.Ctx) error {
 
 	input.Sanitize()
 
-	response := h.service.Login(c.Context(), &input)
+	response := h.service.Login(helpers.ExtractIdentifierAndUsername(c), &input)
 	response.Log = &logDataData
 
 	return helpers.ResponseFormatter(c, response)
@@ -109,7 +115,7 @@ func (h *authHandler) Refresh(c *fiber.Ctx) error {
 		})
 	}
 
-	response := h.service.Refresh(c.Context(), input.Token)
+	response := h.service.Refresh(helpers.ExtractIdentifierAndUsername(c), input.Token)
 	response.Log = &logData
 
 	return helpers.ResponseFormatter(c, response)
@@ -148,3 +154,189 @@ func (h *authHandler) Verify(c *fiber.Ctx) error {
 
 	return helpers.ResponseFormatter(c, response)
 }
+
+func (h *authHandler) VerifyEmail(c *fiber.Ctx) error {
+	logData := helpers.CreateLog(c)
+	var input model.TokenInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+		})
+	}
+
+	input.Sanitize()
+
+	if err := helpers.ValidateInput(input); err != nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+			Log:     &logData,
+		})
+	}
+
+	response := h.service.VerifyEmail(c.Context(), &input)
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *authHandler) ResendVerification(c *fiber.Ctx) error {
+	logData := helpers.CreateLog(c)
+	var input model.EmailInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+		})
+	}
+
+	input.Sanitize()
+
+	if err := helpers.ValidateInput(input); err != nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+			Log:     &logData,
+		})
+	}
+
+	response := h.service.ResendVerification(c.Context(), &input)
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *authHandler) ForgotPassword(c *fiber.Ctx) error {
+	logData := helpers.CreateLog(c)
+	var input model.EmailInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+		})
+	}
+
+	input.Sanitize()
+
+	if err := helpers.ValidateInput(input); err != nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+			Log:     &logData,
+		})
+	}
+
+	response := h.service.ForgotPassword(c.Context(), &input)
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *authHandler) ResetPassword(c *fiber.Ctx) error {
+	logData := helpers.CreateLog(c)
+	var input model.ResetPasswordInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+		})
+	}
+
+	input.Sanitize()
+
+	if err := helpers.ValidateInput(input); err != nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+			Log:     &logData,
+		})
+	}
+
+	response := h.service.ResetPassword(c.Context(), &input)
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *authHandler) Register(c *fiber.Ctx) error {
+	logData := helpers.CreateLog(c)
+	var input model.RegisterInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+		})
+	}
+
+	input.Sanitize()
+
+	if err := helpers.ValidateInput(input); err != nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+			Log:     &logData,
+		})
+	}
+
+	response := h.service.Register(c.Context(), &input)
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *authHandler) ChangeExpiredPassword(c *fiber.Ctx) error {
+	logData := helpers.CreateLog(c)
+	var input model.ExpiredPasswordInput
+
+	if err := c.BodyParser(&input); err != nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+		})
+	}
+
+	input.Sanitize()
+
+	if err := helpers.ValidateInput(input); err != nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  err,
+			Log:     &logData,
+		})
+	}
+
+	response := h.service.ChangeExpiredPassword(c.Context(), &input)
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
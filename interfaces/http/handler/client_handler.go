@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type ClientHandler interface {
+	GetClient(c *fiber.Ctx) error
+	GetAllClient(c *fiber.Ctx) error
+	CreateClient(c *fiber.Ctx) error
+	UpdateClient(c *fiber.Ctx) error
+	RotateClientSecret(c *fiber.Ctx) error
+	DeleteClient(c *fiber.Ctx) error
+}
+
+type clientHandler struct {
+	service service.ClientService
+}
+
+func NewClientHandler(service service.ClientService) ClientHandler {
+	return &clientHandler{
+		service: service,
+	}
+}
+
+func (h *clientHandler) GetClient(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	clientUUID, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid UUID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.GetByUUID(ctx, clientUUID)
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *clientHandler) GetAllClient(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	response := h.service.GetAll(ctx)
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *clientHandler) CreateClient(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var input model.ClientInput
+	var response helpers.BaseResponse
+
+	if err := c.BodyParser(&input); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		input.Sanitize()
+
+		if err := helpers.ValidateInput(input); err != nil {
+			response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request body",
+				Log:     &logData,
+				Errors:  err,
+			})
+		} else {
+			response = h.service.Create(ctx, &input)
+			response.Log = &logData
+		}
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *clientHandler) UpdateClient(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var input model.ClientInput
+	var response helpers.BaseResponse
+
+	clientUUID, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid UUID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else if err := c.BodyParser(&input); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		input.Sanitize()
+
+		if err := helpers.ValidateInput(input); err != nil {
+			response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request body",
+				Log:     &logData,
+				Errors:  err,
+			})
+		} else {
+			response = h.service.UpdateByUUID(ctx, &input, clientUUID)
+			response.Log = &logData
+		}
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *clientHandler) RotateClientSecret(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	clientUUID, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid UUID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.RotateSecretByUUID(ctx, clientUUID)
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *clientHandler) DeleteClient(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	clientUUID, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid UUID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.DeleteByUUID(ctx, clientUUID)
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
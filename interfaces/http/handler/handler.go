@@ -1,13 +1,29 @@
 package handler
 
 type UserManagementHandler struct {
-	UserHandler       UserHandler
-	PermissionHandler PermissionHandler
-	ModuleHandler     ModuleHandler
-	RoleHandler       RoleHandler
+	UserHandler           UserHandler
+	PermissionHandler     PermissionHandler
+	ModuleHandler         ModuleHandler
+	RoleHandler           RoleHandler
+	SessionHandler        SessionHandler
+	ClientHandler         ClientHandler
+	UserGrantHandler      UserGrantHandler
+	UserPermissionHandler UserPermissionHandler
+}
+
+type AuthManagementHandler struct {
+	AuthHandler          AuthHandler
+	TwoFactorHandler     TwoFactorHandler
+	SessionHandler       SessionHandler
+	ApiKeyHandler        ApiKeyHandler
+	OidcHandler          OidcHandler
+	ImpersonationHandler ImpersonationHandler
 }
 
 type Handlers struct {
 	UserManagementHandler *UserManagementHandler
-	AuthHandler           AuthHandler
+	AuthManagementHandler *AuthManagementHandler
+	OAuthHandler          OAuthHandler
+	MeHandler             MeHandler
+	WellKnownHandler      WellKnownHandler
 }
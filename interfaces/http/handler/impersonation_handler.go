@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type ImpersonationHandler interface {
+	StartImpersonation(c *fiber.Ctx) error
+	EndImpersonation(c *fiber.Ctx) error
+}
+
+type impersonationHandler struct {
+	service service.ImpersonationService
+}
+
+func NewImpersonationHandler(service service.ImpersonationService) ImpersonationHandler {
+	return &impersonationHandler{
+		service: service,
+	}
+}
+
+func (h *impersonationHandler) StartImpersonation(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+	var response helpers.BaseResponse
+
+	id, err := strconv.ParseUint(c.Params("user_id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.Start(ctx, uint(id))
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *impersonationHandler) EndImpersonation(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+	var response helpers.BaseResponse
+
+	jti, _ := c.Locals("impersonation_jti").(string)
+	expiredAt, _ := c.Locals("impersonation_expired_at").(time.Time)
+	if jti == "" {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Request is not made with impersonation token",
+			Log:     &logData,
+		})
+	} else {
+		response = h.service.End(ctx, jti, expiredAt)
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
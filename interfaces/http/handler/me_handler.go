@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+// MeHandler serve the authenticated user own account, so no user permission is needed
+type MeHandler interface {
+	GetMe(c *fiber.Ctx) error
+	UpdateMe(c *fiber.Ctx) error
+	ChangeMyPassword(c *fiber.Ctx) error
+	GetMyPermissions(c *fiber.Ctx) error
+}
+
+type meHandler struct {
+	service service.UserService
+}
+
+func NewMeHandler(service service.UserService) MeHandler {
+	return &meHandler{
+		service: service,
+	}
+}
+
+func (h *meHandler) GetMe(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	userID := c.Locals("user_id").(float64)
+
+	response := h.service.GetByID(ctx, uint(userID))
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *meHandler) UpdateMe(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+	var response helpers.BaseResponse
+
+	userID := c.Locals("user_id").(float64)
+
+	var input model.ProfileUpdateInput
+	if err := c.BodyParser(&input); err != nil {
+		response = helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+			Errors:  err,
+		}
+	} else {
+		input.Sanitize()
+
+		if err := helpers.ValidateInput(input); err != nil {
+			response = helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request body",
+				Log:     &logData,
+				Errors:  err,
+			}
+		} else {
+			response = h.service.UpdateProfileByID(ctx, &input, uint(userID))
+			response.Log = &logData
+		}
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *meHandler) ChangeMyPassword(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+	var response helpers.BaseResponse
+
+	userID := c.Locals("user_id").(float64)
+
+	var input model.ChangeOwnPasswordInput
+	if err := c.BodyParser(&input); err != nil {
+		response = helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+			Errors:  err,
+		}
+	} else {
+		input.Sanitize()
+
+		if err := helpers.ValidateInput(input); err != nil {
+			response = helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request body",
+				Log:     &logData,
+				Errors:  err,
+			}
+		} else {
+			response = h.service.ChangeOwnPasswordByID(ctx, &input, uint(userID))
+			response.Log = &logData
+		}
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *meHandler) GetMyPermissions(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	userID := c.Locals("user_id").(float64)
+
+	response := h.service.GetPermissionsByID(ctx, uint(userID))
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type OAuthHandler interface {
+	Token(c *fiber.Ctx) error
+}
+
+type oauthHandler struct {
+	clientService service.ClientService
+}
+
+func NewOAuthHandler(clientService service.ClientService) OAuthHandler {
+	return &oauthHandler{
+		clientService: clientService,
+	}
+}
+
+// Token is OAuth2 token endpoint, response follow RFC 6749 format instead of the usual
+// base response so standard OAuth2 library could consume it directly
+func (h *oauthHandler) Token(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var input model.ClientCredentialsInput
+	var response helpers.BaseResponse
+
+	if err := c.BodyParser(&input); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Errors:  model.OAuthError{Error: "invalid_request", ErrorDescription: "Invalid or malformed request body"},
+		})
+	} else {
+		// Client credentials may come from HTTP basic authentication instead of body
+		if clientID, clientSecret, ok := parseBasicAuth(c.Get(fiber.HeaderAuthorization)); ok {
+			input.ClientID = clientID
+			input.ClientSecret = clientSecret
+		}
+
+		if err := helpers.ValidateInput(input); err != nil || input.ClientID == "" || input.ClientSecret == "" {
+			response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request body",
+				Errors: model.OAuthError{
+					Error:            "invalid_request",
+					ErrorDescription: "grant_type, client_id and client_secret are required",
+				},
+			})
+		} else {
+			response = h.clientService.IssueToken(ctx, &input)
+			logData.Message = response.Message
+		}
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+
+	if !response.Success {
+		if response.Status == fiber.StatusUnauthorized {
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+		}
+
+		oauthError, ok := response.Errors.(model.OAuthError)
+		if !ok {
+			oauthError = model.OAuthError{Error: "server_error"}
+		}
+
+		return c.Status(response.Status).JSON(oauthError)
+	}
+
+	return c.Status(response.Status).JSON(response.Data)
+}
+
+// parseBasicAuth read client id and secret from basic authentication, both part are form
+// url encoded before base64 as required by RFC 6749 section 2.3.1
+func parseBasicAuth(authorization string) (string, string, bool) {
+	encoded, found := strings.CutPrefix(authorization, "Basic ")
+	if !found {
+		return "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", "", false
+	}
+
+	clientID, clientSecret, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return "", "", false
+	}
+
+	clientID, err = url.QueryUnescape(clientID)
+	if err != nil {
+		return "", "", false
+	}
+
+	clientSecret, err = url.QueryUnescape(clientSecret)
+	if err != nil {
+		return "", "", false
+	}
+
+	return clientID, clientSecret, true
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type OidcHandler interface {
+	Login(c *fiber.Ctx) error
+	Callback(c *fiber.Ctx) error
+}
+
+type oidcHandler struct {
+	service service.OidcService
+}
+
+func NewOidcHandler(service service.OidcService) OidcHandler {
+	return &oidcHandler{
+		service: service,
+	}
+}
+
+// Login redirect user agent to identity provider authorization endpoint
+func (h *oidcHandler) Login(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	response := h.service.Login(ctx, c.Params("provider"))
+	response.Log = &logData
+
+	if authorization, ok := response.Data.(model.OidcAuthorization); ok && response.Success {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.Redirect(authorization.AuthorizationURL, fiber.StatusFound)
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *oidcHandler) Callback(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var input model.OidcCallbackInput
+	var response helpers.BaseResponse
+
+	if err := c.QueryParser(&input); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request query",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		input.Sanitize()
+
+		response = h.service.Callback(ctx, c.Params("provider"), &input)
+		response.Log = &logData
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	return helpers.ResponseFormatter(c, response)
+}
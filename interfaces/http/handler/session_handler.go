@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type SessionHandler interface {
+	GetMySessions(c *fiber.Ctx) error
+	RevokeMySession(c *fiber.Ctx) error
+	RevokeMySessions(c *fiber.Ctx) error
+	GetUserSessions(c *fiber.Ctx) error
+	RevokeUserSession(c *fiber.Ctx) error
+	RevokeUserSessions(c *fiber.Ctx) error
+}
+
+type sessionHandler struct {
+	service service.SessionService
+}
+
+func NewSessionHandler(service service.SessionService) SessionHandler {
+	return &sessionHandler{
+		service: service,
+	}
+}
+
+func (h *sessionHandler) GetMySessions(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	userID := c.Locals("user_id").(float64)
+
+	response := h.service.GetAllByUserID(ctx, uint(userID))
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *sessionHandler) RevokeMySession(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	userID := c.Locals("user_id").(float64)
+
+	var response helpers.BaseResponse
+	sessionID, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid UUID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.RevokeByUUID(ctx, uint(userID), sessionID)
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *sessionHandler) RevokeMySessions(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	userID := c.Locals("user_id").(float64)
+
+	response := h.service.RevokeAllByUserID(ctx, uint(userID))
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *sessionHandler) GetUserSessions(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.GetAllByUserID(ctx, uint(id))
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *sessionHandler) RevokeUserSession(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+		return helpers.ResponseFormatter(c, response)
+	}
+
+	sessionID, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid UUID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.RevokeByUUID(ctx, uint(id), sessionID)
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *sessionHandler) RevokeUserSessions(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.RevokeAllByUserID(ctx, uint(id))
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
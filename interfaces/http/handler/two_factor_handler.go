@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type TwoFactorHandler interface {
+	Generate(c *fiber.Ctx) error
+	Confirm(c *fiber.Ctx) error
+	Disable(c *fiber.Ctx) error
+	RegenerateRecoveryCodes(c *fiber.Ctx) error
+	Enroll(c *fiber.Ctx) error
+	Verify(c *fiber.Ctx) error
+}
+
+type twoFactorHandler struct {
+	service service.TwoFactorService
+}
+
+func NewTwoFactorHandler(service service.TwoFactorService) TwoFactorHandler {
+	return &twoFactorHandler{
+		service: service,
+	}
+}
+
+func (h *twoFactorHandler) Generate(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	userID := c.Locals("user_id").(float64)
+
+	response := h.service.Generate(ctx, uint(userID))
+	response.Log = &logData
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *twoFactorHandler) Confirm(c *fiber.Ctx) error {
+	return h.handleCode(c, h.service.Confirm)
+}
+
+func (h *twoFactorHandler) Disable(c *fiber.Ctx) error {
+	return h.handleCode(c, h.service.Disable)
+}
+
+func (h *twoFactorHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	return h.handleCode(c, h.service.RegenerateRecoveryCodes)
+}
+
+func (h *twoFactorHandler) Enroll(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var input model.TwoFactorEnrollInput
+	var response helpers.BaseResponse
+
+	if err := c.BodyParser(&input); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		input.Sanitize()
+
+		if err := helpers.ValidateInput(input); err != nil {
+			response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request body",
+				Log:     &logData,
+				Errors:  err,
+			})
+		} else {
+			response = h.service.Enroll(ctx, &input)
+			response.Log = &logData
+		}
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *twoFactorHandler) Verify(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var input model.TwoFactorVerifyInput
+	var response helpers.BaseResponse
+
+	if err := c.BodyParser(&input); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		input.Sanitize()
+
+		if err := helpers.ValidateInput(input); err != nil {
+			response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request body",
+				Log:     &logData,
+				Errors:  err,
+			})
+		} else {
+			response = h.service.Verify(ctx, &input)
+			response.Log = &logData
+		}
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+// handleCode parse code input for authenticated user then pass it to service function
+func (h *twoFactorHandler) handleCode(
+	c *fiber.Ctx, fn func(ctx context.Context, input *model.TwoFactorCodeInput, userID uint) helpers.BaseResponse,
+) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	userID := c.Locals("user_id").(float64)
+
+	var input model.TwoFactorCodeInput
+	var response helpers.BaseResponse
+
+	if err := c.BodyParser(&input); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		input.Sanitize()
+
+		if err := helpers.ValidateInput(input); err != nil {
+			response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request body",
+				Log:     &logData,
+				Errors:  err,
+			})
+		} else {
+			response = fn(ctx, &input, uint(userID))
+			response.Log = &logData
+		}
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
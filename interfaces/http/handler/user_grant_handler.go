@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type UserGrantHandler interface {
+	GetUserGrants(c *fiber.Ctx) error
+	CreateUserGrant(c *fiber.Ctx) error
+	RevokeUserGrant(c *fiber.Ctx) error
+}
+
+type userGrantHandler struct {
+	service service.UserGrantService
+}
+
+func NewUserGrantHandler(service service.UserGrantService) UserGrantHandler {
+	return &userGrantHandler{
+		service: service,
+	}
+}
+
+func (h *userGrantHandler) GetUserGrants(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.GetAllByUserID(ctx, uint(id))
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *userGrantHandler) CreateUserGrant(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	grantedBy := c.Locals("user_id").(float64)
+
+	var input model.UserGrantInput
+	var response helpers.BaseResponse
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+		return helpers.ResponseFormatter(c, response)
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		input.Sanitize()
+
+		if err := helpers.ValidateInput(input); err != nil {
+			response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request body",
+				Log:     &logData,
+				Errors:  err,
+			})
+		} else {
+			response = h.service.Create(ctx, &input, uint(id), uint(grantedBy))
+			response.Log = &logData
+		}
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *userGrantHandler) RevokeUserGrant(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+		return helpers.ResponseFormatter(c, response)
+	}
+
+	grantID, err := uuid.Parse(c.Params("uuid"))
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid UUID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.RevokeByUUID(ctx, uint(id), grantID)
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
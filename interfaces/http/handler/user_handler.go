@@ -16,6 +16,8 @@ type UserHandler interface {
 	CreateUser(c *fiber.Ctx) error
 	UpdateUser(c *fiber.Ctx) error
 	ResetPassword(c *fiber.Ctx) error
+	UnlockUser(c *fiber.Ctx) error
+	ApproveUser(c *fiber.Ctx) error
 	DeleteUser(c *fiber.Ctx) error
 }
 
@@ -215,6 +217,54 @@ func (h *userHandler) ResetPassword(c *fiber.Ctx) error {
 	return helpers.ResponseFormatter(c, response)
 }
 
+func (h *userHandler) UnlockUser(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+	var response helpers.BaseResponse
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.UnlockByID(ctx, uint(id))
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *userHandler) ApproveUser(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+	var response helpers.BaseResponse
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.ApproveByID(ctx, uint(id))
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
 func (h *userHandler) DeleteUser(c *fiber.Ctx) error {
 	ctx := helpers.ExtractIdentifierAndUsername(c)
 	logData := helpers.CreateLog(h)
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/service"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type UserPermissionHandler interface {
+	GetUserPermissionOverrides(c *fiber.Ctx) error
+	CreateUserPermissionOverride(c *fiber.Ctx) error
+	UpdateUserPermissionOverride(c *fiber.Ctx) error
+	DeleteUserPermissionOverride(c *fiber.Ctx) error
+}
+
+type userPermissionHandler struct {
+	service service.UserPermissionService
+}
+
+func NewUserPermissionHandler(service service.UserPermissionService) UserPermissionHandler {
+	return &userPermissionHandler{
+		service: service,
+	}
+}
+
+func (h *userPermissionHandler) GetUserPermissionOverrides(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.GetAllByUserID(ctx, uint(id))
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *userPermissionHandler) CreateUserPermissionOverride(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	grantedBy := c.Locals("user_id").(float64)
+
+	var input model.UserPermissionInput
+	var response helpers.BaseResponse
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+		return helpers.ResponseFormatter(c, response)
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		input.Sanitize()
+
+		if err := helpers.ValidateInput(input); err != nil {
+			response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request body",
+				Log:     &logData,
+				Errors:  err,
+			})
+		} else {
+			response = h.service.Create(ctx, &input, uint(id), uint(grantedBy))
+			response.Log = &logData
+		}
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *userPermissionHandler) UpdateUserPermissionOverride(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	grantedBy := c.Locals("user_id").(float64)
+
+	var input model.UserPermissionUpdateInput
+	var response helpers.BaseResponse
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+		return helpers.ResponseFormatter(c, response)
+	}
+
+	permissionID, err := strconv.ParseUint(c.Params("permission_id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+		return helpers.ResponseFormatter(c, response)
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid or malformed request body",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		input.Sanitize()
+
+		if err := helpers.ValidateInput(input); err != nil {
+			response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+				Status:  fiber.StatusBadRequest,
+				Success: false,
+				Message: "Invalid or malformed request body",
+				Log:     &logData,
+				Errors:  err,
+			})
+		} else {
+			response = h.service.UpdateByPermissionID(ctx, &input, uint(id), uint(permissionID), uint(grantedBy))
+			response.Log = &logData
+		}
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
+
+func (h *userPermissionHandler) DeleteUserPermissionOverride(c *fiber.Ctx) error {
+	ctx := helpers.ExtractIdentifierAndUsername(c)
+	logData := helpers.CreateLog(h)
+
+	defer helpers.LogSystemWithDefer(ctx, &logData)
+
+	var response helpers.BaseResponse
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+		return helpers.ResponseFormatter(c, response)
+	}
+
+	permissionID, err := strconv.ParseUint(c.Params("permission_id"), 10, 64)
+	if err != nil {
+		response = helpers.LogBaseResponse(&logData, helpers.BaseResponse{
+			Status:  fiber.StatusBadRequest,
+			Success: false,
+			Message: "Invalid ID format",
+			Log:     &logData,
+			Errors:  err,
+		})
+	} else {
+		response = h.service.DeleteByPermissionID(ctx, uint(id), uint(permissionID))
+		response.Log = &logData
+	}
+
+	return helpers.ResponseFormatter(c, response)
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+)
+
+type WellKnownHandler interface {
+	JWKS(c *fiber.Ctx) error
+}
+
+type wellKnownHandler struct{}
+
+func NewWellKnownHandler() WellKnownHandler {
+	return &wellKnownHandler{}
+}
+
+// JWKS publish access token verification keys, response follow RFC 7517 format instead
+// of the usual base response so standard JWT library could consume it directly
+func (h *wellKnownHandler) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+
+	return c.JSON(helpers.AccessKeyRing().JWKS())
+}
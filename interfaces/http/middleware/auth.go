@@ -1,20 +1,101 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/model"
 	"github.com/sayyidinside/gofiber-clean-fresh/pkg/helpers"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
 )
 
+// TokenDenyList report whether access token id already revoked before its expiry
+type TokenDenyList interface {
+	IsDenied(ctx context.Context, jti string) bool
+}
+
+// Global variable to hold deny list checked by Authentication
+var tokenDenyList TokenDenyList
+
+// InitTokenDenyList set deny list used by Authentication, when never set revoked
+// access token stay valid until expired
+func InitTokenDenyList(denyList TokenDenyList) {
+	tokenDenyList = denyList
+}
+
+// PermissionResolver resolve permission names of role, used in runtime permission mode
+type PermissionResolver interface {
+	GetPermissionNames(ctx context.Context, roleID uint) ([]string, error)
+}
+
+// Global variable to hold permission resolver used by Authorization
+var permissionResolver PermissionResolver
+
+// InitPermissionResolver set resolver used by Authorization when token carry no permissions
+func InitPermissionResolver(resolver PermissionResolver) {
+	permissionResolver = resolver
+}
+
+// PermissionExpander expand granted permissions with permissions they imply (e.g. wildcard)
+type PermissionExpander interface {
+	Expand(ctx context.Context, permissions []string) ([]string, error)
+}
+
+// Global variable to hold permission expander used by Authorization
+var permissionExpander PermissionExpander
+
+// InitPermissionExpander set expander used by Authorization, when never set permission
+// is matched only by exact name
+func InitPermissionExpander(expander PermissionExpander) {
+	permissionExpander = expander
+}
+
+// TokenVersion report whether version embedded in access token still the current one
+type TokenVersion interface {
+	IsCurrent(ctx context.Context, userID uint, userVersion uint, roleVersions map[uint]uint) bool
+	IsClientCurrent(ctx context.Context, clientID string, clientVersion uint) bool
+}
+
+// Global variable to hold token version checked by Authentication
+var tokenVersion TokenVersion
+
+// InitTokenVersion set token version checker used by Authentication
+func InitTokenVersion(version TokenVersion) {
+	tokenVersion = version
+}
+
+// ApiKeyAuthenticator resolve api key into the identity it act for
+type ApiKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string, ip string) (*model.ApiKeyPrincipal, error)
+}
+
+// Global variable to hold api key authenticator used by Authentication
+var apiKeyAuthenticator ApiKeyAuthenticator
+
+// InitApiKeyAuthenticator set authenticator used when request carry api key instead of access token
+func InitApiKeyAuthenticator(authenticator ApiKeyAuthenticator) {
+	apiKeyAuthenticator = authenticator
+}
+
 // Authentication to check and validate user access token, then set value from claim
 // to context local as payload to be used in other layers
 func Authentication() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		cfg := config.AppConfig
 		authorization := c.Get("Authorization")
+
+		// Machine client could authenticate with api key instead of access token
+		if apiKey := c.Get("X-API-Key"); apiKey != "" {
+			return authenticateApiKey(c, apiKey)
+		}
+		if apiKey, found := strings.CutPrefix(authorization, "ApiKey "); found {
+			return authenticateApiKey(c, apiKey)
+		}
+
 		if authorization == "" {
 			return helpers.ResponseFormatter(c, helpers.BaseResponse{
 				Status:  fiber.StatusUnauthorized,
@@ -24,7 +105,7 @@ func Authentication() fiber.Handler {
 		}
 
 		access_token := strings.TrimPrefix(authorization, "Bearer ")
-		claim, err := helpers.ValidateToken(access_token, cfg.JwtAccessPublicSecret)
+		claim, err := helpers.ValidateToken(access_token, helpers.AccessKeyRing())
 		if err != nil {
 			if err.Error() == "validate: token has invalid claims: token is expired" {
 				return helpers.ResponseFormatter(c, helpers.BaseResponse{
@@ -41,8 +122,8 @@ func Authentication() fiber.Handler {
 			})
 		}
 
-		user_id, ok := claim["sub"].(float64)
-		if !ok {
+		// Challenge token (e.g. two-factor) is signed with the same key but must not grant access
+		if _, exists := claim["purpose"]; exists {
 			return helpers.ResponseFormatter(c, helpers.BaseResponse{
 				Status:  fiber.StatusUnauthorized,
 				Success: false,
@@ -50,7 +131,21 @@ func Authentication() fiber.Handler {
 			})
 		}
 
-		username, ok := claim["username"].(string)
+		// Token revoked by logout, password change, role or permission change and user deletion
+		if jti, _ := claim["jti"].(string); tokenDenyList != nil && tokenDenyList.IsDenied(c.Context(), jti) {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusUnauthorized,
+				Success: false,
+				Message: "Token has been revoked",
+			})
+		}
+
+		// Service principal from client credentials grant carry no user identity
+		if client_id, ok := claim["client_id"].(string); ok {
+			return authenticateClient(c, claim, client_id)
+		}
+
+		user_id, ok := claim["sub"].(float64)
 		if !ok {
 			return helpers.ResponseFormatter(c, helpers.BaseResponse{
 				Status:  fiber.StatusUnauthorized,
@@ -59,7 +154,36 @@ func Authentication() fiber.Handler {
 			})
 		}
 
-		email, ok := claim["email"].(string)
+		role_versions := roleVersions(claim)
+
+		// Role or permission changed after token issued, client need to refresh for new claim
+		if tokenVersion != nil {
+			user_version, _ := claim["ver"].(float64)
+
+			if !tokenVersion.IsCurrent(c.Context(), uint(user_id), uint(user_version), role_versions) {
+				return helpers.ResponseFormatter(c, helpers.BaseResponse{
+					Status:  fiber.StatusUnauthorized,
+					Success: false,
+					Message: "Token is outdated, please refresh",
+				})
+			}
+
+			// Impersonation end once the admin acting as the user is demoted or deleted
+			if actor, ok := claim["act"].(map[string]interface{}); ok {
+				actor_id, _ := actor["sub"].(float64)
+				actor_version, _ := actor["ver"].(float64)
+
+				if !tokenVersion.IsCurrent(c.Context(), uint(actor_id), uint(actor_version), roleVersions(actor)) {
+					return helpers.ResponseFormatter(c, helpers.BaseResponse{
+						Status:  fiber.StatusUnauthorized,
+						Success: false,
+						Message: "Impersonation has been revoked",
+					})
+				}
+			}
+		}
+
+		username, ok := claim["username"].(string)
 		if !ok {
 			return helpers.ResponseFormatter(c, helpers.BaseResponse{
 				Status:  fiber.StatusUnauthorized,
@@ -68,7 +192,7 @@ func Authentication() fiber.Handler {
 			})
 		}
 
-		is_admin, ok := claim["is_admin"].(bool)
+		email, ok := claim["email"].(string)
 		if !ok {
 			return helpers.ResponseFormatter(c, helpers.BaseResponse{
 				Status:  fiber.StatusUnauthorized,
@@ -77,7 +201,7 @@ func Authentication() fiber.Handler {
 			})
 		}
 
-		validated, ok := claim["validated"].(bool)
+		is_admin, ok := claim["is_admin"].(bool)
 		if !ok {
 			return helpers.ResponseFormatter(c, helpers.BaseResponse{
 				Status:  fiber.StatusUnauthorized,
@@ -86,7 +210,7 @@ func Authentication() fiber.Handler {
 			})
 		}
 
-		validated_at, ok := claim["validated_at"].(float64)
+		validated, ok := claim["validated"].(bool)
 		if !ok {
 			return helpers.ResponseFormatter(c, helpers.BaseResponse{
 				Status:  fiber.StatusUnauthorized,
@@ -95,7 +219,7 @@ func Authentication() fiber.Handler {
 			})
 		}
 
-		permissionInterfaces, ok := claim["permissions"].([]any)
+		validated_at, ok := claim["validated_at"].(float64)
 		if !ok {
 			return helpers.ResponseFormatter(c, helpers.BaseResponse{
 				Status:  fiber.StatusUnauthorized,
@@ -104,10 +228,28 @@ func Authentication() fiber.Handler {
 			})
 		}
 
+		role_id, _ := claim["role_id"].(float64)
+
+		// In runtime mode token carry no permissions, they are resolved later by Authorization
 		var permissions []string
-		for _, permission := range permissionInterfaces {
-			if perm, ok := permission.(string); ok {
-				permissions = append(permissions, perm)
+		var granted_permissions []string
+		if config.AppConfig.PermissionMode == constant.PermissionModeRuntime {
+			granted_permissions = claimStrings(claim, "granted_permissions")
+		} else {
+			permissionInterfaces, ok := claim["permissions"].([]any)
+			if !ok {
+				return helpers.ResponseFormatter(c, helpers.BaseResponse{
+					Status:  fiber.StatusUnauthorized,
+					Success: false,
+					Message: "Invalid token",
+				})
+			}
+
+			permissions = []string{}
+			for _, permission := range permissionInterfaces {
+				if perm, ok := permission.(string); ok {
+					permissions = append(permissions, perm)
+				}
 			}
 		}
 
@@ -115,9 +257,147 @@ func Authentication() fiber.Handler {
 		c.Locals("username", username)
 		c.Locals("email", email)
 		c.Locals("is_admin", is_admin)
+		c.Locals("role_id", uint(role_id))
+		c.Locals("role_ids", roleIDs(role_versions))
 		c.Locals("validated", validated)
 		c.Locals("validated_at", time.Unix(int64(validated_at), 0))
-		c.Locals("permissions", permissions)
+		if permissions != nil {
+			c.Locals("permissions", permissions)
+		}
+		c.Locals("granted_permissions", granted_permissions)
+		c.Locals("denied_permissions", claimStrings(claim, "denied_permissions"))
+
+		// Impersonation token name the admin acting as the user, kept for audit and ending it
+		if actor, ok := claim["act"].(map[string]interface{}); ok {
+			impersonator_id, _ := actor["sub"].(float64)
+			impersonator, _ := actor["username"].(string)
+			jti, _ := claim["jti"].(string)
+			expired_at, _ := claim["exp"].(float64)
+
+			c.Locals("impersonator_id", impersonator_id)
+			c.Locals("impersonator", impersonator)
+			c.Locals("impersonation_jti", jti)
+			c.Locals("impersonation_expired_at", time.Unix(int64(expired_at), 0))
+		}
+
+		return c.Next()
+	}
+}
+
+// authenticateApiKey set context local from api key owner, the key never act as admin
+// and only carry permissions in its scope
+func authenticateApiKey(c *fiber.Ctx, key string) error {
+	if apiKeyAuthenticator == nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusUnauthorized,
+			Success: false,
+			Message: "Api key authentication not available",
+		})
+	}
+
+	principal, err := apiKeyAuthenticator.Authenticate(c.Context(), strings.TrimSpace(key), c.IP())
+	if err != nil {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusUnauthorized,
+			Success: false,
+			Message: "Invalid api key",
+		})
+	}
+
+	c.Locals("user_id", float64(principal.UserID))
+	c.Locals("username", principal.Username)
+	c.Locals("email", principal.Email)
+	c.Locals("is_admin", false)
+	c.Locals("role_id", principal.RoleID)
+	c.Locals("role_ids", principal.RoleIDs)
+	c.Locals("validated", principal.Validated)
+	c.Locals("validated_at", principal.ValidatedAt)
+	c.Locals("permissions", principal.Permissions)
+	c.Locals("denied_permissions", principal.DeniedPermissions)
+	c.Locals("api_key_id", principal.ApiKeyID)
+
+	return c.Next()
+}
+
+// authenticateClient set context local for OAuth2 client, the client has no user, username
+// nor role and only carry permissions granted as scope when token issued
+func authenticateClient(c *fiber.Ctx, claim map[string]interface{}, client_id string) error {
+	// Client secret rotated, scope changed or client deleted after token issued
+	if tokenVersion != nil {
+		client_version, _ := claim["client_ver"].(float64)
+
+		if !tokenVersion.IsClientCurrent(c.Context(), client_id, uint(client_version)) {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusUnauthorized,
+				Success: false,
+				Message: "Token has been revoked",
+			})
+		}
+	}
+
+	permissionInterfaces, ok := claim["permissions"].([]any)
+	if !ok {
+		return helpers.ResponseFormatter(c, helpers.BaseResponse{
+			Status:  fiber.StatusUnauthorized,
+			Success: false,
+			Message: "Invalid token",
+		})
+	}
+
+	permissions := []string{}
+	for _, permission := range permissionInterfaces {
+		if perm, ok := permission.(string); ok {
+			permissions = append(permissions, perm)
+		}
+	}
+
+	issued_at, _ := claim["iat"].(float64)
+
+	c.Locals("user_id", float64(0))
+	c.Locals("username", "")
+	c.Locals("email", "")
+	c.Locals("is_admin", false)
+	c.Locals("role_id", uint(0))
+	c.Locals("role_ids", []uint{})
+	c.Locals("validated", true)
+	c.Locals("validated_at", time.Unix(int64(issued_at), 0))
+	c.Locals("permissions", permissions)
+	c.Locals("client_id", client_id)
+
+	return c.Next()
+}
+
+// DenyMachineClient reject request authenticated by api key or OAuth2 client, used on endpoint
+// that manage credential (session, two-factor, api key) where interactive login is required
+//
+// ! Important, that this middleware be called or used after Authentication middleware
+func DenyMachineClient() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals("api_key_id") != nil || c.Locals("client_id") != nil {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusForbidden,
+				Success: false,
+				Message: "Machine client is not allowed to access this resource",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// DenyImpersonation reject request made by admin impersonating user, used on endpoint that manage
+// credential of the user (password, session, two-factor, api key) which only the owner should change
+//
+// ! Important, that this middleware be called or used after Authentication middleware
+func DenyImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals("impersonator_id") != nil {
+			return helpers.ResponseFormatter(c, helpers.BaseResponse{
+				Status:  fiber.StatusForbidden,
+				Success: false,
+				Message: "Not allowed while impersonating user",
+			})
+		}
 
 		return c.Next()
 	}
@@ -150,7 +430,38 @@ func Authorization(isAdminOnly bool, isValidOnly bool, allowedPermissions []stri
 		}
 
 		// Check allowed permission against user permissions
-		userPermissions := c.Locals("permissions").([]string)
+		userPermissions, ok := c.Locals("permissions").([]string)
+		if !ok {
+			resolved, err := resolvePermissions(c)
+			if err != nil {
+				return helpers.ResponseFormatter(c, helpers.BaseResponse{
+					Status:  fiber.StatusInternalServerError,
+					Success: false,
+					Message: "Error resolving permissions",
+					Errors:  err,
+				})
+			}
+
+			userPermissions = resolved
+		}
+
+		// Wildcard and declared implication grant more than what is listed
+		if permissionExpander != nil && len(allowedPermissions) != 0 {
+			expanded, err := permissionExpander.Expand(c.Context(), userPermissions)
+			if err != nil {
+				return helpers.ResponseFormatter(c, helpers.BaseResponse{
+					Status:  fiber.StatusInternalServerError,
+					Success: false,
+					Message: "Error resolving permissions",
+					Errors:  err,
+				})
+			}
+
+			userPermissions = expanded
+		}
+
+		// Permission denied to user directly win, even when granted by role or implied by another
+		userPermissions = withoutDenied(c, userPermissions)
 		if len(userPermissions) > len(allowedPermissions) {
 			// Create map from the smallest slice
 			permissionMap := make(map[string]struct{}, len(allowedPermissions))
@@ -190,3 +501,118 @@ func Authorization(isAdminOnly bool, isValidOnly bool, allowedPermissions []stri
 		})
 	}
 }
+
+// resolvePermissions resolve union of permissions of every authenticated user role, along with
+// permission granted directly to user, once per request
+func resolvePermissions(c *fiber.Ctx) ([]string, error) {
+	if permissionResolver == nil {
+		return nil, fmt.Errorf("permission resolver not initialized")
+	}
+
+	roleIDs, _ := c.Locals("role_ids").([]uint)
+	if len(roleIDs) == 0 {
+		if roleID, _ := c.Locals("role_id").(uint); roleID != 0 {
+			roleIDs = []uint{roleID}
+		}
+	}
+
+	seen := map[string]struct{}{}
+	permissions := []string{}
+	for _, roleID := range roleIDs {
+		names, err := permissionResolver.GetPermissionNames(c.Context(), roleID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			if _, exists := seen[name]; !exists {
+				seen[name] = struct{}{}
+				permissions = append(permissions, name)
+			}
+		}
+	}
+
+	granted, _ := c.Locals("granted_permissions").([]string)
+	for _, name := range granted {
+		if _, exists := seen[name]; !exists {
+			seen[name] = struct{}{}
+			permissions = append(permissions, name)
+		}
+	}
+
+	c.Locals("permissions", permissions)
+
+	return permissions, nil
+}
+
+// withoutDenied remove permission denied to authenticated user by override
+func withoutDenied(c *fiber.Ctx, permissions []string) []string {
+	denied, _ := c.Locals("denied_permissions").([]string)
+	if len(denied) == 0 {
+		return permissions
+	}
+
+	deniedMap := make(map[string]struct{}, len(denied))
+	for _, perm := range denied {
+		deniedMap[perm] = struct{}{}
+	}
+
+	allowed := make([]string, 0, len(permissions))
+	for _, perm := range permissions {
+		if _, exists := deniedMap[perm]; !exists {
+			allowed = append(allowed, perm)
+		}
+	}
+
+	return allowed
+}
+
+// claimStrings read claim holding list of string, missing claim result in empty list
+func claimStrings(claim map[string]interface{}, key string) []string {
+	values := []string{}
+
+	interfaces, _ := claim[key].([]any)
+	for _, value := range interfaces {
+		if str, ok := value.(string); ok {
+			values = append(values, str)
+		}
+	}
+
+	return values
+}
+
+// roleVersions read token version of every role embedded in claim, token issued before
+// multiple role supported only carry role_id and role_ver
+func roleVersions(claim map[string]interface{}) map[uint]uint {
+	versions := map[uint]uint{}
+
+	if roles, ok := claim["roles"].(map[string]interface{}); ok {
+		for id, version := range roles {
+			role_id, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			role_version, _ := version.(float64)
+			versions[uint(role_id)] = uint(role_version)
+		}
+
+		return versions
+	}
+
+	if role_id, _ := claim["role_id"].(float64); role_id != 0 {
+		role_version, _ := claim["role_ver"].(float64)
+		versions[uint(role_id)] = uint(role_version)
+	}
+
+	return versions
+}
+
+func roleIDs(roleVersions map[uint]uint) []uint {
+	ids := make([]uint, 0, len(roleVersions))
+	for id := range roleVersions {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
@@ -27,6 +27,7 @@ func Setup(app *fiber.App, handlers *handler.Handlers) {
 	api.Use(middleware.Cache())
 
 	v1.RegisterRoutes(api, handlers)
+	RegisterWellKnownRoutes(app, handlers.WellKnownHandler)
 	tests.SetupApiTestRoutes(test)
 
 	app.Get("/", func(c *fiber.Ctx) error {
@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterApiKeyRoutes(route fiber.Router, handler handler.ApiKeyHandler) {
+	apiKey := route.Group("/api-keys")
+
+	// Key management need real login, an api key or client could not mint or revoke key
+	apiKey.Use(middleware.Authentication(), middleware.DenyMachineClient(), middleware.DenyImpersonation())
+
+	apiKey.Get("/", handler.GetAllApiKey)
+	apiKey.Post("/", handler.CreateApiKey)
+	apiKey.Delete("/:uuid", handler.RevokeApiKey)
+}
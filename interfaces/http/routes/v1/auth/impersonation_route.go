@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterImpersonationRoutes(route fiber.Router, handler handler.ImpersonationHandler) {
+	impersonate := route.Group("/impersonate")
+
+	impersonate.Use(middleware.Authentication(), middleware.DenyMachineClient())
+
+	// Ended with the impersonation token itself, so the admin session is left untouched
+	impersonate.Delete("/", handler.EndImpersonation)
+
+	impersonate.Post(
+		"/:user_id",
+		middleware.DenyImpersonation(),
+		middleware.Authorization(true, false, []string{}),
+		handler.StartImpersonation,
+	)
+}
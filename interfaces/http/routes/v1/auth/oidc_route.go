@@ -0,0 +1,13 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+)
+
+func RegisterOidcRoutes(route fiber.Router, handler handler.OidcHandler) {
+	oidc := route.Group("/oidc/:provider")
+
+	oidc.Get("/login", handler.Login)
+	oidc.Get("/callback", handler.Callback)
+}
@@ -6,11 +6,23 @@ import (
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
 )
 
-func RegisterRoutes(route fiber.Router, handler handler.AuthHandler) {
+func RegisterRoutes(route fiber.Router, handler *handler.AuthManagementHandler) {
 	authRoutes := route.Group("/auth")
 
-	authRoutes.Post("/login", handler.Login)
-	authRoutes.Get("/verify", handler.Verify)
-	authRoutes.Post("/refresh", handler.Refresh)
-	authRoutes.Post("/logout", middleware.Authentication(), handler.Logout)
+	authRoutes.Post("/login", handler.AuthHandler.Login)
+	authRoutes.Post("/register", handler.AuthHandler.Register)
+	authRoutes.Get("/verify", handler.AuthHandler.Verify)
+	authRoutes.Post("/refresh", handler.AuthHandler.Refresh)
+	authRoutes.Post("/logout", middleware.Authentication(), handler.AuthHandler.Logout)
+	authRoutes.Post("/verify-email", handler.AuthHandler.VerifyEmail)
+	authRoutes.Post("/verify-email/resend", handler.AuthHandler.ResendVerification)
+	authRoutes.Post("/forgot-password", handler.AuthHandler.ForgotPassword)
+	authRoutes.Post("/reset-password", handler.AuthHandler.ResetPassword)
+	authRoutes.Post("/expired-password", handler.AuthHandler.ChangeExpiredPassword)
+
+	RegisterTwoFactorRoutes(authRoutes, handler.TwoFactorHandler)
+	RegisterSessionRoutes(authRoutes, handler.SessionHandler)
+	RegisterApiKeyRoutes(authRoutes, handler.ApiKeyHandler)
+	RegisterOidcRoutes(authRoutes, handler.OidcHandler)
+	RegisterImpersonationRoutes(authRoutes, handler.ImpersonationHandler)
 }
@@ -0,0 +1,17 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterSessionRoutes(route fiber.Router, handler handler.SessionHandler) {
+	session := route.Group("/sessions")
+
+	session.Use(middleware.Authentication(), middleware.DenyMachineClient(), middleware.DenyImpersonation())
+
+	session.Get("/", handler.GetMySessions)
+	session.Delete("/", handler.RevokeMySessions)
+	session.Delete("/:uuid", handler.RevokeMySession)
+}
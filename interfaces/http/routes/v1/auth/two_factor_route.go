@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterTwoFactorRoutes(route fiber.Router, handler handler.TwoFactorHandler) {
+	twoFactor := route.Group("/2fa")
+
+	// Second step of login, authorized by challenge token instead of access token
+	twoFactor.Post("/enroll", handler.Enroll)
+	twoFactor.Post("/verify", handler.Verify)
+
+	twoFactor.Post("/generate", middleware.Authentication(), middleware.DenyMachineClient(), middleware.DenyImpersonation(), handler.Generate)
+	twoFactor.Post("/confirm", middleware.Authentication(), middleware.DenyMachineClient(), middleware.DenyImpersonation(), handler.Confirm)
+	twoFactor.Post("/disable", middleware.Authentication(), middleware.DenyMachineClient(), middleware.DenyImpersonation(), handler.Disable)
+	twoFactor.Post("/recovery-codes", middleware.Authentication(), middleware.DenyMachineClient(), middleware.DenyImpersonation(), handler.RegenerateRecoveryCodes)
+}
@@ -0,0 +1,21 @@
+package me
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterRoutes(route fiber.Router, handler handler.MeHandler) {
+	me := route.Group("/me")
+
+	// OAuth2 client has no user account behind it
+	me.Use(middleware.Authentication(), middleware.DenyMachineClient())
+
+	me.Get("/", handler.GetMe)
+	me.Get("/permissions", handler.GetMyPermissions)
+
+	// Account change need the owner themself
+	me.Put("/", middleware.DenyImpersonation(), handler.UpdateMe)
+	me.Put("/password", middleware.DenyImpersonation(), handler.ChangeMyPassword)
+}
@@ -0,0 +1,12 @@
+package oauth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+)
+
+func RegisterRoutes(route fiber.Router, handler handler.OAuthHandler) {
+	oauth := route.Group("/oauth")
+
+	oauth.Post("/token", handler.Token)
+}
@@ -4,6 +4,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/routes/v1/auth"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/routes/v1/me"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/routes/v1/oauth"
 	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/routes/v1/users"
 )
 
@@ -11,5 +13,7 @@ func RegisterRoutes(route fiber.Router, handler *handler.Handlers) {
 	v1 := route.Group("/v1")
 
 	users.RegisterRoutes(v1, handler.UserManagementHandler)
-	auth.RegisterRoutes(v1, handler.AuthHandler)
+	auth.RegisterRoutes(v1, handler.AuthManagementHandler)
+	oauth.RegisterRoutes(v1, handler.OAuthHandler)
+	me.RegisterRoutes(v1, handler.MeHandler)
 }
@@ -0,0 +1,49 @@
+package users
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterClientRoutes(route fiber.Router, handler handler.ClientHandler) {
+	client := route.Group("/clients")
+
+	client.Use(middleware.Authentication(), middleware.DenyMachineClient())
+
+	client.Get(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetClient,
+	)
+
+	client.Get(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetAllClient,
+	)
+
+	client.Post(
+		"",
+		middleware.Authorization(true, false, []string{}),
+		handler.CreateClient,
+	)
+
+	client.Put(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.UpdateClient,
+	)
+
+	client.Post(
+		"/:uuid/secret",
+		middleware.Authorization(true, false, []string{}),
+		handler.RotateClientSecret,
+	)
+
+	client.Delete(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.DeleteClient,
+	)
+}
@@ -0,0 +1,30 @@
+package users
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterUserGrantRoutes(route fiber.Router, handler handler.UserGrantHandler) {
+	// Authenticated by "/data" group of user routes
+	grant := route.Group("/data/:id/grants")
+
+	grant.Get(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetUserGrants,
+	)
+
+	grant.Post(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.CreateUserGrant,
+	)
+
+	grant.Delete(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.RevokeUserGrant,
+	)
+}
@@ -15,9 +15,6 @@ func RegisterPermissionRoutes(route fiber.Router, handler handler.PermissionHand
 		"/",
 		middleware.Authorization(false, false, []string{
 			"View Permission",
-			"Create Permission",
-			"Update Permission",
-			"Delete Permission",
 		}),
 		handler.GetAllPermission,
 	)
@@ -26,9 +23,6 @@ func RegisterPermissionRoutes(route fiber.Router, handler handler.PermissionHand
 		"/:id",
 		middleware.Authorization(false, false, []string{
 			"View Permission",
-			"Create Permission",
-			"Update Permission",
-			"Delete Permission",
 		}),
 		handler.GetPermission,
 	)
@@ -15,9 +15,6 @@ func RegisterRoleRoutes(route fiber.Router, handler handler.RoleHandler) {
 		"/:id",
 		middleware.Authorization(false, false, []string{
 			"View Role",
-			"Create Role",
-			"Update Role",
-			"Delete Role",
 		}),
 		handler.GetRole,
 	)
@@ -26,9 +23,6 @@ func RegisterRoleRoutes(route fiber.Router, handler handler.RoleHandler) {
 		"/",
 		middleware.Authorization(false, false, []string{
 			"View Role",
-			"Create Role",
-			"Update Role",
-			"Delete Role",
 		}),
 		handler.GetAllRole,
 	)
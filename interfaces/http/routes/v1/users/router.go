@@ -12,4 +12,8 @@ func RegisterRoutes(route fiber.Router, handler *handler.UserManagementHandler)
 	RegisterPermissionRoutes(user, handler.PermissionHandler)
 	RegisterModuleRoutes(user, handler.ModuleHandler)
 	RegisterRoleRoutes(user, handler.RoleHandler)
+	RegisterUserSessionRoutes(user, handler.SessionHandler)
+	RegisterUserGrantRoutes(user, handler.UserGrantHandler)
+	RegisterUserPermissionRoutes(user, handler.UserPermissionHandler)
+	RegisterClientRoutes(user, handler.ClientHandler)
 }
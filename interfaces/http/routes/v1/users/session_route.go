@@ -0,0 +1,30 @@
+package users
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterUserSessionRoutes(route fiber.Router, handler handler.SessionHandler) {
+	// Authenticated by "/data" group of user routes
+	session := route.Group("/data/:id/sessions")
+
+	session.Get(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetUserSessions,
+	)
+
+	session.Delete(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.RevokeUserSessions,
+	)
+
+	session.Delete(
+		"/:uuid",
+		middleware.Authorization(true, false, []string{}),
+		handler.RevokeUserSession,
+	)
+}
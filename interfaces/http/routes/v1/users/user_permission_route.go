@@ -0,0 +1,36 @@
+package users
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/middleware"
+)
+
+func RegisterUserPermissionRoutes(route fiber.Router, handler handler.UserPermissionHandler) {
+	// Authenticated by "/data" group of user routes
+	override := route.Group("/data/:id/permissions")
+
+	override.Get(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.GetUserPermissionOverrides,
+	)
+
+	override.Post(
+		"/",
+		middleware.Authorization(true, false, []string{}),
+		handler.CreateUserPermissionOverride,
+	)
+
+	override.Put(
+		"/:permission_id",
+		middleware.Authorization(true, false, []string{}),
+		handler.UpdateUserPermissionOverride,
+	)
+
+	override.Delete(
+		"/:permission_id",
+		middleware.Authorization(true, false, []string{}),
+		handler.DeleteUserPermissionOverride,
+	)
+}
@@ -15,9 +15,6 @@ func RegisterUserRoutes(route fiber.Router, handler handler.UserHandler) {
 		"/:id",
 		middleware.Authorization(false, false, []string{
 			"View User",
-			"Create User",
-			"Update User",
-			"Delete User",
 		}),
 		handler.GetUser,
 	)
@@ -26,9 +23,6 @@ func RegisterUserRoutes(route fiber.Router, handler handler.UserHandler) {
 		"/",
 		middleware.Authorization(false, false, []string{
 			"View User",
-			"Create User",
-			"Update User",
-			"Delete User",
 		}),
 		handler.GetAllUser,
 	)
@@ -47,6 +41,18 @@ func RegisterUserRoutes(route fiber.Router, handler handler.UserHandler) {
 		handler.ResetPassword,
 	)
 
+	user.Put(
+		"/:id/unlock",
+		middleware.Authorization(true, false, []string{}),
+		handler.UnlockUser,
+	)
+
+	user.Put(
+		"/:id/approve",
+		middleware.Authorization(true, false, []string{}),
+		handler.ApproveUser,
+	)
+
 	user.Put(
 		"/:id",
 		middleware.Authorization(false, false, []string{
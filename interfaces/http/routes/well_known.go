@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/sayyidinside/gofiber-clean-fresh/interfaces/http/handler"
+)
+
+func RegisterWellKnownRoutes(app *fiber.App, handler handler.WellKnownHandler) {
+	wellKnown := app.Group("/.well-known")
+
+	wellKnown.Get("/jwks.json", handler.JWKS)
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+)
+
+type (
+	ApiKeyList struct {
+		UUID        uuid.UUID  `json:"uuid"`
+		Name        string     `json:"name"`
+		Prefix      string     `json:"prefix"`
+		Permissions []string   `json:"permissions"`
+		ExpiredAt   time.Time  `json:"expired_at"`
+		LastUsedAt  *time.Time `json:"last_used_at"`
+		LastUsedIP  string     `json:"last_used_ip"`
+		CreatedAt   time.Time  `json:"created_at"`
+	}
+
+	// ApiKeyCreated is the only response that contain plaintext key
+	ApiKeyCreated struct {
+		ApiKeyList
+		Key string `json:"key"`
+	}
+
+	// ApiKeyPrincipal is identity authenticated by api key, consumed by Authentication middleware
+	ApiKeyPrincipal struct {
+		ApiKeyID    uint
+		UserID      uint
+		Username    string
+		Email       string
+		RoleID      uint
+		RoleIDs     []uint
+		Validated   bool
+		ValidatedAt time.Time
+		Permissions []string
+
+		// Denied to owner by override, removed again after permission implication expanded
+		DeniedPermissions []string
+	}
+
+	ApiKeyInput struct {
+		Name        string `json:"name" form:"name" xml:"name" validate:"required,max=100"`
+		Permissions []uint `json:"permissions" form:"permissions" xml:"permissions" validate:"required,gt=0,dive,numeric"`
+		ExpiresIn   int    `json:"expires_in" form:"expires_in" xml:"expires_in" validate:"required,min=1,max=365"` // In days
+	}
+)
+
+func (input *ApiKeyInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Name = sanitizer.Sanitize(input.Name)
+}
+
+func (input *ApiKeyInput) ToEntity() *entity.ApiKey {
+	return &entity.ApiKey{
+		Name:      input.Name,
+		ExpiredAt: time.Now().AddDate(0, 0, input.ExpiresIn),
+	}
+}
+
+func ApiKeyToListModel(apiKey *entity.ApiKey) *ApiKeyList {
+	permissions := []string{}
+	for _, permission := range apiKey.Permissions {
+		permissions = append(permissions, permission.Name)
+	}
+
+	var lastUsedAt *time.Time
+	if apiKey.LastUsedAt.Valid {
+		lastUsedAt = &apiKey.LastUsedAt.Time
+	}
+
+	return &ApiKeyList{
+		UUID:        apiKey.UUID,
+		Name:        apiKey.Name,
+		Prefix:      apiKey.Prefix,
+		Permissions: permissions,
+		ExpiredAt:   apiKey.ExpiredAt,
+		LastUsedAt:  lastUsedAt,
+		LastUsedIP:  apiKey.LastUsedIP,
+		CreatedAt:   apiKey.CreatedAt,
+	}
+}
+
+func ApiKeyToListModels(apiKeys *[]entity.ApiKey) *[]ApiKeyList {
+	listModels := []ApiKeyList{}
+
+	for _, apiKey := range *apiKeys {
+		listModels = append(listModels, *ApiKeyToListModel(&apiKey))
+	}
+
+	return &listModels
+}
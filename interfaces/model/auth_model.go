@@ -1,6 +1,11 @@
 package model
 
-import "github.com/microcosm-cc/bluemonday"
+import (
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+)
 
 type (
 	LoginInput struct {
@@ -11,6 +16,42 @@ type (
 	TokenInput struct {
 		Token string `json:"token" form:"token" xml:"token" validate:"required"`
 	}
+
+	EmailInput struct {
+		Email string `json:"email" form:"email" xml:"email" validate:"required,email"`
+	}
+
+	// LoginThrottle tell client how long to wait before next login attempt
+	LoginThrottle struct {
+		RetryAfter int `json:"retry_after"` // In seconds
+	}
+
+	RegisterInput struct {
+		Username   string `json:"username" form:"username" xml:"username" validate:"required"`
+		Email      string `json:"email" form:"email" xml:"email" validate:"required,email"`
+		Password   string `json:"password" form:"password" xml:"password" validate:"required"`
+		RePassword string `json:"repassword" form:"repassword" xml:"repassword" validate:"required,eqfield=Password"`
+	}
+
+	ResetPasswordInput struct {
+		Token      string `json:"token" form:"token" xml:"token" validate:"required"`
+		Password   string `json:"password" form:"password" xml:"password" validate:"required"`
+		RePassword string `json:"repassword" form:"repassword" xml:"repassword" validate:"required,eqfield=Password"`
+	}
+
+	// PasswordChangeChallenge is returned by login when password is older than allowed, recovery
+	// codes are included when second factor was enrolled in the same login
+	PasswordChangeChallenge struct {
+		ChallengeToken string    `json:"challenge_token"`
+		ExpiredAt      time.Time `json:"expired_at"`
+		RecoveryCodes  []string  `json:"recovery_codes,omitempty"`
+	}
+
+	ExpiredPasswordInput struct {
+		ChallengeToken string `json:"challenge_token" form:"challenge_token" xml:"challenge_token" validate:"required"`
+		Password       string `json:"password" form:"password" xml:"password" validate:"required"`
+		RePassword     string `json:"repassword" form:"repassword" xml:"repassword" validate:"required,eqfield=Password"`
+	}
 )
 
 func (input *LoginInput) Sanitize() {
@@ -25,3 +66,43 @@ func (input *TokenInput) Sanitize() {
 
 	input.Token = sanitizer.Sanitize(input.Token)
 }
+
+func (input *EmailInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Email = sanitizer.Sanitize(input.Email)
+}
+
+func (input *RegisterInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Username = sanitizer.Sanitize(input.Username)
+	input.Email = sanitizer.Sanitize(input.Email)
+	input.Password = sanitizer.Sanitize(input.Password)
+	input.RePassword = sanitizer.Sanitize(input.RePassword)
+}
+
+// ToEntity map input into new user, password is hashed by entity BeforeCreate hook
+func (input *RegisterInput) ToEntity() *entity.User {
+	return &entity.User{
+		Username: input.Username,
+		Email:    input.Email,
+		Password: input.Password,
+	}
+}
+
+func (input *ResetPasswordInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Token = sanitizer.Sanitize(input.Token)
+	input.Password = sanitizer.Sanitize(input.Password)
+	input.RePassword = sanitizer.Sanitize(input.RePassword)
+}
+
+func (input *ExpiredPasswordInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.ChallengeToken = sanitizer.Sanitize(input.ChallengeToken)
+	input.Password = sanitizer.Sanitize(input.Password)
+	input.RePassword = sanitizer.Sanitize(input.RePassword)
+}
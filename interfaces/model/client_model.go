@@ -0,0 +1,97 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils"
+)
+
+type (
+	ClientList struct {
+		UUID        uuid.UUID `json:"uuid"`
+		ClientID    string    `json:"client_id"`
+		Name        string    `json:"name"`
+		Scopes      []string  `json:"scopes"`
+		Permissions []string  `json:"permissions"`
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   time.Time `json:"updated_at"`
+	}
+
+	// ClientCreated is the only response that contain plaintext secret
+	ClientCreated struct {
+		ClientList
+		ClientSecret string `json:"client_secret"`
+	}
+
+	ClientInput struct {
+		Name   string `json:"name" form:"name" xml:"name" validate:"required,max=100"`
+		Scopes []uint `json:"scopes" form:"scopes" xml:"scopes" validate:"required,gt=0,dive,numeric"` // Permission id
+	}
+
+	// ClientCredentialsInput is token request of client credentials grant (RFC 6749 section 4.4),
+	// client id and secret may also be sent through HTTP basic authentication
+	ClientCredentialsInput struct {
+		GrantType    string `json:"grant_type" form:"grant_type" xml:"grant_type" validate:"required"`
+		ClientID     string `json:"client_id" form:"client_id" xml:"client_id"`
+		ClientSecret string `json:"client_secret" form:"client_secret" xml:"client_secret"`
+		Scope        string `json:"scope" form:"scope" xml:"scope"`
+	}
+
+	// OAuthToken is successful token response (RFC 6749 section 5.1)
+	OAuthToken struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int    `json:"expires_in"`
+		Scope       string `json:"scope"`
+	}
+
+	// OAuthError is error token response (RFC 6749 section 5.2)
+	OAuthError struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description,omitempty"`
+	}
+)
+
+func (input *ClientInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Name = sanitizer.Sanitize(input.Name)
+}
+
+func (input *ClientInput) ToEntity() *entity.Client {
+	return &entity.Client{
+		Name: input.Name,
+	}
+}
+
+func ClientToListModel(client *entity.Client) *ClientList {
+	scopes := []string{}
+	permissions := []string{}
+	for _, permission := range client.Scopes {
+		scopes = append(scopes, utils.PermissionToScope(permission.Name))
+		permissions = append(permissions, permission.Name)
+	}
+
+	return &ClientList{
+		UUID:        client.UUID,
+		ClientID:    client.ClientID,
+		Name:        client.Name,
+		Scopes:      scopes,
+		Permissions: permissions,
+		CreatedAt:   client.CreatedAt,
+		UpdatedAt:   client.UpdatedAt,
+	}
+}
+
+func ClientToListModels(clients *[]entity.Client) *[]ClientList {
+	listModels := []ClientList{}
+
+	for _, client := range *clients {
+		listModels = append(listModels, *ClientToListModel(&client))
+	}
+
+	return &listModels
+}
@@ -0,0 +1,24 @@
+package model
+
+import "github.com/microcosm-cc/bluemonday"
+
+type (
+	// OidcCallbackInput is authorization response sent by provider to redirect uri
+	OidcCallbackInput struct {
+		Code             string `query:"code"`
+		State            string `query:"state"`
+		Error            string `query:"error"`
+		ErrorDescription string `query:"error_description"`
+	}
+
+	OidcAuthorization struct {
+		AuthorizationURL string `json:"authorization_url"`
+	}
+)
+
+func (input *OidcCallbackInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Error = sanitizer.Sanitize(input.Error)
+	input.ErrorDescription = sanitizer.Sanitize(input.ErrorDescription)
+}
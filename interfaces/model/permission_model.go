@@ -10,13 +10,14 @@ import (
 
 type (
 	PermissionDetail struct {
-		ID        uint      `json:"id"`
-		UUID      uuid.UUID `json:"uuid"`
-		Name      string    `json:"name"`
-		Module    string    `json:"module"`
-		ModuleID  uint      `json:"module_id"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
+		ID        uint              `json:"id"`
+		UUID      uuid.UUID         `json:"uuid"`
+		Name      string            `json:"name"`
+		Module    string            `json:"module"`
+		ModuleID  uint              `json:"module_id"`
+		Implies   *[]PermissionList `json:"implies"`
+		CreatedAt time.Time         `json:"created_at"`
+		UpdatedAt time.Time         `json:"updated_at"`
 	}
 
 	PermissionList struct {
@@ -30,6 +31,9 @@ type (
 	PermissionInput struct {
 		Name     string `json:"name" form:"name" xml:"name" validate:"required"`
 		ModuleID uint   `json:"module_id" form:"module_id" xml:"module_id" validate:"required,numeric"`
+
+		// Implies hold id of permissions granted along with this one
+		Implies []uint `json:"implies" form:"implies" xml:"implies" validate:"dive,numeric"`
 	}
 )
 
@@ -40,6 +44,7 @@ func PermissionToDetailModel(permission *entity.Permission) *PermissionDetail {
 		Name:      permission.Name,
 		Module:    permission.Module.Name,
 		ModuleID:  permission.Module.ID,
+		Implies:   PermissionToListModels(&permission.Implies),
 		CreatedAt: permission.CreatedAt,
 		UpdatedAt: permission.UpdatedAt,
 	}
@@ -72,9 +77,18 @@ func (input *PermissionInput) Sanitize() {
 }
 
 func (input *PermissionInput) ToEntity() *entity.Permission {
+	implies := []entity.Permission{}
+	seen := map[uint]struct{}{}
+	for _, id := range input.Implies {
+		if _, exists := seen[id]; !exists {
+			seen[id] = struct{}{}
+			implies = append(implies, entity.Permission{ID: id})
+		}
+	}
 
 	return &entity.Permission{
 		Name:     input.Name,
 		ModuleID: input.ModuleID,
+		Implies:  implies,
 	}
 }
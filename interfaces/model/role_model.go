@@ -8,11 +8,22 @@ import (
 
 type (
 	RoleDetail struct {
-		ID          uint              `json:"id"`
-		UUID        uuid.UUID         `json:"uuid"`
-		Name        string            `json:"name"`
-		IsAdmin     bool              `json:"is_admin"`
-		Permissions *[]PermissionList `json:"permissions"`
+		ID               uint              `json:"id"`
+		UUID             uuid.UUID         `json:"uuid"`
+		Name             string            `json:"name"`
+		IsAdmin          bool              `json:"is_admin"`
+		RequireTwoFactor bool              `json:"require_two_factor"`
+		Parents          *[]RoleList       `json:"parents"`
+		Permissions      *[]PermissionList `json:"permissions"`
+
+		// InheritedPermissions hold permission granted by ancestor role that the role does not
+		// have directly, attributed to the nearest ancestor granting it
+		InheritedPermissions *[]InheritedPermissionList `json:"inherited_permissions"`
+	}
+
+	InheritedPermissionList struct {
+		PermissionList
+		InheritedFrom string `json:"inherited_from"`
 	}
 
 	RoleList struct {
@@ -22,20 +33,48 @@ type (
 	}
 
 	RoleInput struct {
-		Name        string `json:"name" form:"name" xml:"name" validate:"required"`
-		IsAdmin     bool   `json:"is_admin" form:"is_admin" xml:"is_admin" validate:"boolean"`
-		Permissions []uint `json:"permissions" form:"permissions" xml:"permissions" validate:"required,gt=0,dive,numeric"`
+		Name             string `json:"name" form:"name" xml:"name" validate:"required"`
+		IsAdmin          bool   `json:"is_admin" form:"is_admin" xml:"is_admin" validate:"boolean"`
+		RequireTwoFactor bool   `json:"require_two_factor" form:"require_two_factor" xml:"require_two_factor" validate:"boolean"`
+		Permissions      []uint `json:"permissions" form:"permissions" xml:"permissions" validate:"required,gt=0,dive,numeric"`
+		ParentIDs        []uint `json:"parent_ids" form:"parent_ids" xml:"parent_ids" validate:"dive,numeric"`
 	}
 )
 
-func RoleToDetailModel(role *entity.Role) *RoleDetail {
+// RoleToDetailModel map role with its direct permissions, and permissions inherited from
+// ancestors which must be ordered nearest first
+func RoleToDetailModel(role *entity.Role, ancestors *[]entity.Role) *RoleDetail {
 	permissions := PermissionToListModels(&role.Permissions)
 
+	seen := map[uint]struct{}{}
+	for _, permission := range role.Permissions {
+		seen[permission.ID] = struct{}{}
+	}
+
+	inherited := []InheritedPermissionList{}
+	for _, ancestor := range *ancestors {
+		for _, permission := range ancestor.Permissions {
+			if _, exists := seen[permission.ID]; exists {
+				continue
+			}
+
+			seen[permission.ID] = struct{}{}
+			inherited = append(inherited, InheritedPermissionList{
+				PermissionList: *PermissionToListModel(&permission),
+				InheritedFrom:  ancestor.Name,
+			})
+		}
+	}
+
 	return &RoleDetail{
-		ID:          role.ID,
-		UUID:        role.UUID,
-		Name:        role.Name,
-		Permissions: permissions,
+		ID:                   role.ID,
+		UUID:                 role.UUID,
+		Name:                 role.Name,
+		IsAdmin:              role.IsAdmin,
+		RequireTwoFactor:     role.RequireTwoFactor,
+		Parents:              RoleToListModels(&role.Parents),
+		Permissions:          permissions,
+		InheritedPermissions: &inherited,
 	}
 }
 
@@ -58,9 +97,20 @@ func RoleToListModels(role *[]entity.Role) *[]RoleList {
 }
 
 func (input *RoleInput) ToEntity() *entity.Role {
+	parents := []entity.Role{}
+	seen := map[uint]struct{}{}
+	for _, id := range input.ParentIDs {
+		if _, exists := seen[id]; !exists {
+			seen[id] = struct{}{}
+			parents = append(parents, entity.Role{ID: id})
+		}
+	}
+
 	return &entity.Role{
-		Name:    input.Name,
-		IsAdmin: input.IsAdmin,
+		Name:             input.Name,
+		IsAdmin:          input.IsAdmin,
+		RequireTwoFactor: input.RequireTwoFactor,
+		Parents:          parents,
 	}
 }
 
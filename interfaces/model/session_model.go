@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+)
+
+type (
+	SessionList struct {
+		UUID       uuid.UUID `json:"uuid"`
+		IPAddress  string    `json:"ip_address"`
+		UserAgent  string    `json:"user_agent"`
+		Device     string    `json:"device"`
+		CreatedAt  time.Time `json:"created_at"`
+		LastUsedAt time.Time `json:"last_used_at"`
+		ExpiredAt  time.Time `json:"expired_at"`
+	}
+)
+
+// SessionToListModel map active refresh token into session, session is identified by
+// token family so the id stay the same across refresh
+func SessionToListModel(token *entity.RefreshToken) *SessionList {
+	lastUsedAt := token.CreatedAt
+	if token.LastUsedAt.Valid {
+		lastUsedAt = token.LastUsedAt.Time
+	}
+
+	return &SessionList{
+		UUID:       token.SessionID(),
+		IPAddress:  token.IPAddress,
+		UserAgent:  token.UserAgent,
+		Device:     token.Device,
+		LastUsedAt: lastUsedAt,
+		CreatedAt:  token.CreatedAt,
+		ExpiredAt:  token.ExpiredAt,
+	}
+}
+
+func SessionToListModels(tokens *[]entity.RefreshToken) *[]SessionList {
+	listModels := []SessionList{}
+
+	for _, token := range *tokens {
+		listModels = append(listModels, *SessionToListModel(&token))
+	}
+
+	return &listModels
+}
@@ -12,6 +12,15 @@ type (
 		AccessToken  string `json:"access_token"`
 	}
 
+	// ImpersonationToken is short lived access token letting admin act as user, no refresh token
+	// is issued so impersonation end on its own once expired
+	ImpersonationToken struct {
+		AccessToken string    `json:"access_token"`
+		ExpiredAt   time.Time `json:"expired_at"`
+		UserID      uint      `json:"user_id"`
+		Username    string    `json:"username"`
+	}
+
 	RefreshTokenData struct {
 		UUID      uuid.UUID `json:"uuid"`
 		UserID    uint      `json:"user_id"`
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+type (
+	TwoFactorSecret struct {
+		Secret string `json:"secret"`
+		URI    string `json:"otpauth_uri"`
+	}
+
+	TwoFactorChallenge struct {
+		ChallengeToken     string    `json:"challenge_token"`
+		ExpiredAt          time.Time `json:"expired_at"`
+		EnrollmentRequired bool      `json:"enrollment_required"`
+	}
+
+	TwoFactorRecoveryCodes struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+
+	TwoFactorLogin struct {
+		AllToken
+		RecoveryCodes []string `json:"recovery_codes,omitempty"`
+	}
+
+	TwoFactorCodeInput struct {
+		Code string `json:"code" form:"code" xml:"code" validate:"required"`
+	}
+
+	TwoFactorEnrollInput struct {
+		ChallengeToken string `json:"challenge_token" form:"challenge_token" xml:"challenge_token" validate:"required"`
+	}
+
+	TwoFactorVerifyInput struct {
+		ChallengeToken string `json:"challenge_token" form:"challenge_token" xml:"challenge_token" validate:"required"`
+		Code           string `json:"code" form:"code" xml:"code" validate:"required"`
+	}
+)
+
+func (input *TwoFactorCodeInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Code = sanitizer.Sanitize(input.Code)
+}
+
+func (input *TwoFactorEnrollInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.ChallengeToken = sanitizer.Sanitize(input.ChallengeToken)
+}
+
+func (input *TwoFactorVerifyInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.ChallengeToken = sanitizer.Sanitize(input.ChallengeToken)
+	input.Code = sanitizer.Sanitize(input.Code)
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+)
+
+const (
+	UserGrantStatusScheduled string = "scheduled"
+	UserGrantStatusActive    string = "active"
+	UserGrantStatusExpired   string = "expired"
+)
+
+type (
+	UserGrantList struct {
+		UUID         uuid.UUID  `json:"uuid"`
+		RoleID       *uint      `json:"role_id"`
+		Role         string     `json:"role,omitempty"`
+		PermissionID *uint      `json:"permission_id"`
+		Permission   string     `json:"permission,omitempty"`
+		ValidFrom    time.Time  `json:"valid_from"`
+		ValidUntil   *time.Time `json:"valid_until"`
+		Status       string     `json:"status"`
+		Reason       string     `json:"reason"`
+		GrantedBy    uint       `json:"granted_by"`
+		CreatedAt    time.Time  `json:"created_at"`
+	}
+
+	// UserGrantInput grant either a role or a single permission, grant without valid_from start
+	// immediately and grant without valid_until never expire
+	UserGrantInput struct {
+		RoleID       *uint      `json:"role_id" form:"role_id" xml:"role_id" validate:"required_without=PermissionID,excluded_with=PermissionID"`
+		PermissionID *uint      `json:"permission_id" form:"permission_id" xml:"permission_id" validate:"required_without=RoleID,excluded_with=RoleID"`
+		ValidFrom    *time.Time `json:"valid_from" form:"valid_from" xml:"valid_from"`
+		ValidUntil   *time.Time `json:"valid_until" form:"valid_until" xml:"valid_until"`
+		Reason       string     `json:"reason" form:"reason" xml:"reason" validate:"max=255"`
+	}
+)
+
+func (input *UserGrantInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Reason = sanitizer.Sanitize(input.Reason)
+}
+
+func (input *UserGrantInput) ToEntity(userID uint, grantedBy uint) *entity.UserGrant {
+	validFrom := time.Now()
+	if input.ValidFrom != nil {
+		validFrom = *input.ValidFrom
+	}
+
+	var validUntil sql.NullTime
+	if input.ValidUntil != nil {
+		validUntil = sql.NullTime{Time: *input.ValidUntil, Valid: true}
+	}
+
+	return &entity.UserGrant{
+		UserID:       userID,
+		RoleID:       input.RoleID,
+		PermissionID: input.PermissionID,
+		ValidFrom:    validFrom,
+		ValidUntil:   validUntil,
+		Reason:       input.Reason,
+		GrantedBy:    grantedBy,
+	}
+}
+
+func UserGrantToListModel(grant *entity.UserGrant) *UserGrantList {
+	var validUntil *time.Time
+	if grant.ValidUntil.Valid {
+		validUntil = &grant.ValidUntil.Time
+	}
+
+	now := time.Now()
+	status := UserGrantStatusActive
+	if grant.ValidFrom.After(now) {
+		status = UserGrantStatusScheduled
+	} else if !grant.IsActive(now) || grant.ExpiredAt.Valid {
+		status = UserGrantStatusExpired
+	}
+
+	return &UserGrantList{
+		UUID:         grant.UUID,
+		RoleID:       grant.RoleID,
+		Role:         grant.Role.Name,
+		PermissionID: grant.PermissionID,
+		Permission:   grant.Permission.Name,
+		ValidFrom:    grant.ValidFrom,
+		ValidUntil:   validUntil,
+		Status:       status,
+		Reason:       grant.Reason,
+		GrantedBy:    grant.GrantedBy,
+		CreatedAt:    grant.CreatedAt,
+	}
+}
+
+func UserGrantToListModels(grants *[]entity.UserGrant) *[]UserGrantList {
+	listModels := []UserGrantList{}
+
+	for _, grant := range *grants {
+		listModels = append(listModels, *UserGrantToListModel(&grant))
+	}
+
+	return &listModels
+}
@@ -12,15 +12,18 @@ import (
 
 type (
 	UserDetail struct {
-		ID          uint         `json:"id"`
-		UUID        uuid.UUID    `json:"uuid"`
-		RoleID      uint         `json:"role_id"`
-		Role        string       `json:"role"`
-		Username    string       `json:"username"`
-		Email       string       `json:"email"`
-		ValidatedAt sql.NullTime `json:"validated_at"`
-		CreatedAt   time.Time    `json:"created_at"`
-		UpdatedAt   time.Time    `json:"updated_at"`
+		ID              uint         `json:"id"`
+		UUID            uuid.UUID    `json:"uuid"`
+		RoleID          uint         `json:"role_id"`
+		Role            string       `json:"role"`
+		RoleIDs         []uint       `json:"role_ids"`
+		Roles           []string     `json:"roles"`
+		Username        string       `json:"username"`
+		Email           string       `json:"email"`
+		ValidatedAt     sql.NullTime `json:"validated_at"`
+		PendingApproval bool         `json:"pending_approval"`
+		CreatedAt       time.Time    `json:"created_at"`
+		UpdatedAt       time.Time    `json:"updated_at"`
 	}
 
 	LogUserInfo struct {
@@ -34,6 +37,7 @@ type (
 		Username string    `json:"username"`
 		Email    string    `json:"email"`
 		Role     string    `json:"role"`
+		Roles    []string  `json:"roles"`
 	}
 
 	UserInput struct {
@@ -41,32 +45,73 @@ type (
 		Email      string `json:"email" form:"email" validate:"required"`
 		Password   string `json:"password" form:"password" validate:"required"`
 		RePassword string `json:"repassword" form:"repassword" validate:"required,eqfield=Password"`
-		RoleID     uint   `json:"role_id" form:"role_id" validate:"required"`
+
+		// RoleID is the primary role, when omitted the first of RoleIDs is used
+		RoleID  uint   `json:"role_id" form:"role_id" validate:"required_without=RoleIDs"`
+		RoleIDs []uint `json:"role_ids" form:"role_ids" validate:"required_without=RoleID,dive,required"`
 	}
 
 	UserUpdateInput struct {
 		Username string `json:"username" form:"username" validate:"required"`
 		Email    string `json:"email" form:"email" validate:"required"`
-		RoleID   uint   `json:"role_id" form:"role_id" validate:"required"`
+		RoleID   uint   `json:"role_id" form:"role_id" validate:"required_without=RoleIDs"`
+		RoleIDs  []uint `json:"role_ids" form:"role_ids" validate:"required_without=RoleID,dive,required"`
 	}
 
 	ChangePasswordInput struct {
 		Password   string `json:"password" form:"password" validate:"required"`
 		RePassword string `json:"repassword" form:"repassword" validate:"required,eqfield=Password"`
 	}
+
+	// ProfileUpdateInput is used by user editing their own account, role could not be changed
+	ProfileUpdateInput struct {
+		Username string `json:"username" form:"username" validate:"required"`
+		Email    string `json:"email" form:"email" validate:"required,email"`
+	}
+
+	ChangeOwnPasswordInput struct {
+		OldPassword string `json:"old_password" form:"old_password" validate:"required"`
+		Password    string `json:"password" form:"password" validate:"required"`
+		RePassword  string `json:"repassword" form:"repassword" validate:"required,eqfield=Password"`
+	}
+
+	UserPermissions struct {
+		RoleID            uint     `json:"role_id"`
+		Role              string   `json:"role"`
+		Roles             []string `json:"roles"`
+		IsAdmin           bool     `json:"is_admin"`
+		Permissions       []string `json:"permissions"`
+		DeniedPermissions []string `json:"denied_permissions"`
+	}
 )
 
 func UserToDetailModel(user *entity.User) *UserDetail {
 	return &UserDetail{
-		ID:          user.ID,
-		UUID:        user.UUID,
-		RoleID:      user.RoleID,
-		Role:        user.Role.Name,
-		Username:    user.Username,
-		Email:       user.Email,
-		ValidatedAt: user.ValidatedAt,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
+		ID:              user.ID,
+		UUID:            user.UUID,
+		RoleID:          user.RoleID,
+		Role:            user.Role.Name,
+		RoleIDs:         user.RoleIDs(),
+		Roles:           roleNames(user.AllRoles()),
+		Username:        user.Username,
+		Email:           user.Email,
+		ValidatedAt:     user.ValidatedAt,
+		PendingApproval: user.PendingApproval,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+}
+
+// UserToPermissionsModel map user into union of permissions granted by all of its roles, grants
+// in effect and allow override without denied one, user must be loaded with roles and their permissions
+func UserToPermissionsModel(user *entity.User) *UserPermissions {
+	return &UserPermissions{
+		RoleID:            user.RoleID,
+		Role:              user.Role.Name,
+		Roles:             roleNames(user.EffectiveRoles()),
+		IsAdmin:           user.IsAdmin(),
+		Permissions:       user.PermissionNames(),
+		DeniedPermissions: user.DeniedPermissionNames(),
 	}
 }
 
@@ -77,9 +122,19 @@ func UserToModel(user *entity.User) *UserList {
 		Username: user.Username,
 		Email:    user.Email,
 		Role:     user.Role.Name,
+		Roles:    roleNames(user.AllRoles()),
 	}
 }
 
+func roleNames(roles []entity.Role) []string {
+	names := []string{}
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+
+	return names
+}
+
 func UserToListModel(users *[]entity.User) *[]UserList {
 	listUsers := []UserList{}
 
@@ -90,15 +145,15 @@ func UserToListModel(users *[]entity.User) *[]UserList {
 	return &listUsers
 }
 
+// ToEntity map input into new user, password is hashed by entity BeforeCreate hook and
+// user start unvalidated until the email verification is completed
 func (userInput *UserInput) ToEntity() *entity.User {
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(userInput.Password), bcrypt.DefaultCost)
-
 	return &entity.User{
-		Username:    userInput.Username,
-		Email:       userInput.Email,
-		Password:    string(hashedPassword),
-		RoleID:      userInput.RoleID,
-		ValidatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		Username: userInput.Username,
+		Email:    userInput.Email,
+		Password: userInput.Password,
+		RoleID:   primaryRoleID(userInput.RoleID, userInput.RoleIDs),
+		Roles:    toRoles(userInput.RoleID, userInput.RoleIDs),
 	}
 }
 
@@ -107,7 +162,42 @@ func (input *UserUpdateInput) ToEntity() *entity.User {
 	return &entity.User{
 		Username: input.Username,
 		Email:    input.Email,
-		RoleID:   input.RoleID,
+		RoleID:   primaryRoleID(input.RoleID, input.RoleIDs),
+		Roles:    toRoles(input.RoleID, input.RoleIDs),
+	}
+}
+
+// primaryRoleID pick given primary role, falling back to the first of assigned roles
+func primaryRoleID(roleID uint, roleIDs []uint) uint {
+	if roleID == 0 && len(roleIDs) != 0 {
+		return roleIDs[0]
+	}
+
+	return roleID
+}
+
+// toRoles build distinct role reference from primary role and assigned roles, primary role
+// is always part of assigned roles
+func toRoles(roleID uint, roleIDs []uint) []entity.Role {
+	roles := []entity.Role{}
+	seen := map[uint]struct{}{}
+
+	for _, id := range append([]uint{primaryRoleID(roleID, roleIDs)}, roleIDs...) {
+		if _, exists := seen[id]; exists || id == 0 {
+			continue
+		}
+
+		seen[id] = struct{}{}
+		roles = append(roles, entity.Role{ID: id})
+	}
+
+	return roles
+}
+
+func (input *ProfileUpdateInput) ToEntity() *entity.User {
+	return &entity.User{
+		Username: input.Username,
+		Email:    input.Email,
 	}
 }
 
@@ -140,3 +230,18 @@ func (input *ChangePasswordInput) Sanitize() {
 	input.Password = sanitizer.Sanitize(input.Password)
 	input.RePassword = sanitizer.Sanitize(input.RePassword)
 }
+
+func (input *ProfileUpdateInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Username = sanitizer.Sanitize(input.Username)
+	input.Email = sanitizer.Sanitize(input.Email)
+}
+
+func (input *ChangeOwnPasswordInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.OldPassword = sanitizer.Sanitize(input.OldPassword)
+	input.Password = sanitizer.Sanitize(input.Password)
+	input.RePassword = sanitizer.Sanitize(input.RePassword)
+}
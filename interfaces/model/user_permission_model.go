@@ -0,0 +1,87 @@
+package model
+
+import (
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+)
+
+type (
+	UserPermissionList struct {
+		PermissionID uint      `json:"permission_id"`
+		Permission   string    `json:"permission"`
+		Effect       string    `json:"effect"`
+		Reason       string    `json:"reason"`
+		GrantedBy    uint      `json:"granted_by"`
+		CreatedAt    time.Time `json:"created_at"`
+		UpdatedAt    time.Time `json:"updated_at"`
+	}
+
+	UserPermissionInput struct {
+		PermissionID uint   `json:"permission_id" form:"permission_id" xml:"permission_id" validate:"required,numeric"`
+		Effect       string `json:"effect" form:"effect" xml:"effect" validate:"required,oneof=allow deny"`
+		Reason       string `json:"reason" form:"reason" xml:"reason" validate:"max=255"`
+	}
+
+	UserPermissionUpdateInput struct {
+		Effect string `json:"effect" form:"effect" xml:"effect" validate:"required,oneof=allow deny"`
+		Reason string `json:"reason" form:"reason" xml:"reason" validate:"max=255"`
+	}
+)
+
+func (input *UserPermissionInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Effect = sanitizer.Sanitize(input.Effect)
+	input.Reason = sanitizer.Sanitize(input.Reason)
+}
+
+func (input *UserPermissionInput) ToEntity(userID uint, grantedBy uint) *entity.UserPermission {
+	return &entity.UserPermission{
+		UserID:       userID,
+		PermissionID: input.PermissionID,
+		Effect:       input.Effect,
+		Reason:       input.Reason,
+		GrantedBy:    grantedBy,
+	}
+}
+
+func (input *UserPermissionUpdateInput) Sanitize() {
+	sanitizer := bluemonday.StrictPolicy()
+
+	input.Effect = sanitizer.Sanitize(input.Effect)
+	input.Reason = sanitizer.Sanitize(input.Reason)
+}
+
+func (input *UserPermissionUpdateInput) ToEntity(userID uint, permissionID uint, grantedBy uint) *entity.UserPermission {
+	return &entity.UserPermission{
+		UserID:       userID,
+		PermissionID: permissionID,
+		Effect:       input.Effect,
+		Reason:       input.Reason,
+		GrantedBy:    grantedBy,
+	}
+}
+
+func UserPermissionToListModel(override *entity.UserPermission) *UserPermissionList {
+	return &UserPermissionList{
+		PermissionID: override.PermissionID,
+		Permission:   override.Permission.Name,
+		Effect:       override.Effect,
+		Reason:       override.Reason,
+		GrantedBy:    override.GrantedBy,
+		CreatedAt:    override.CreatedAt,
+		UpdatedAt:    override.UpdatedAt,
+	}
+}
+
+func UserPermissionToListModels(overrides *[]entity.UserPermission) *[]UserPermissionList {
+	listModels := []UserPermissionList{}
+
+	for _, override := range *overrides {
+		listModels = append(listModels, *UserPermissionToListModel(&override))
+	}
+
+	return &listModels
+}
@@ -0,0 +1,13 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashToken return hex encoded SHA-256 digest of opaque token, used to store token
+// that only need to be matched (reset token, refresh token, api key) instead of plaintext
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
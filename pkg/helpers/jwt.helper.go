@@ -1,50 +1,125 @@
 package helpers
 
 import (
-	"encoding/base64"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/sayyidinside/gofiber-clean-fresh/domain/entity"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils"
+	"github.com/sayyidinside/gofiber-clean-fresh/pkg/utils/constant"
 )
 
-func GenerateToken(user *entity.User, expireTime int, secret string, isRefresh bool) (string, error) {
-	decodedSecret, err := base64.StdEncoding.DecodeString(secret)
-	if err != nil {
-		return "", fmt.Errorf("could not decode token secret: %w", err)
+// GenerateToken sign access or refresh token for user, jti must be unique per token so
+// two rotations in the same second never collide and access token could be revoked by id
+func GenerateToken(user *entity.User, jti string, expireTime int, keyRing *KeyRing, isRefresh bool) (string, error) {
+	claim := make(jwt.MapClaims)
+	claim["sub"] = user.ID
+	claim["jti"] = jti
+	claim["iat"] = time.Now().Unix()
+	claim["nbf"] = time.Now().Unix()
+
+	if isRefresh {
+		claim["exp"] = time.Now().Add(time.Duration(expireTime) * time.Hour).Unix()
+	} else {
+		claim["exp"] = time.Now().Add(time.Duration(expireTime) * time.Minute).Unix()
+
+		setAccessClaim(claim, user)
 	}
 
-	key, err := jwt.ParseRSAPrivateKeyFromPEM(decodedSecret)
+	token, err := keyRing.Sign(claim)
 	if err != nil {
-		return "", fmt.Errorf("create: parse token secret key: %w", err)
+		return "", fmt.Errorf("could not generate token: %w", err)
 	}
 
+	return token, nil
+}
+
+// GenerateImpersonationToken sign access token for user on behalf of actor (admin), the actor is
+// kept in "act" claim (RFC 8693) so every request made with it could be traced to the admin, its
+// token version is kept too so token is rejected once the admin is demoted or deleted
+func GenerateImpersonationToken(user *entity.User, actor *entity.User, jti string, expireTime int, keyRing *KeyRing) (string, error) {
 	claim := make(jwt.MapClaims)
 	claim["sub"] = user.ID
+	claim["jti"] = jti
 	claim["iat"] = time.Now().Unix()
 	claim["nbf"] = time.Now().Unix()
+	claim["exp"] = time.Now().Add(time.Duration(expireTime) * time.Minute).Unix()
+	claim["act"] = map[string]interface{}{
+		"sub":      actor.ID,
+		"username": actor.Username,
+		"ver":      actor.TokenVersion,
+		"roles":    roleVersionClaim(actor),
+	}
+	setAccessClaim(claim, user)
 
-	if isRefresh {
-		claim["exp"] = time.Now().Add(time.Duration(expireTime) * time.Hour).Unix()
+	token, err := keyRing.Sign(claim)
+	if err != nil {
+		return "", fmt.Errorf("could not generate token: %w", err)
+	}
+
+	return token, nil
+}
+
+// setAccessClaim fill identity, role and permission claim of user into access token
+func setAccessClaim(claim jwt.MapClaims, user *entity.User) {
+	claim["name"] = user.Username
+	claim["username"] = user.Username
+	claim["email"] = user.Email
+	claim["is_admin"] = user.IsAdmin()
+	claim["role_id"] = user.RoleID
+	claim["ver"] = user.TokenVersion
+	claim["validated"] = user.ValidatedAt.Valid
+	claim["validated_at"] = user.ValidatedAt.Time.Unix()
+
+	// Token version of every assigned or granted role keyed by role id, so change on any of them reject the token
+	claim["roles"] = roleVersionClaim(user)
+
+	// Runtime mode keep token small, permissions resolved from roles on every request, only
+	// permission given directly to user is carried since it belong to no role
+	if config.AppConfig.PermissionMode != constant.PermissionModeRuntime {
+		claim["permissions"] = user.PermissionNames()
 	} else {
-		claim["exp"] = time.Now().Add(time.Duration(expireTime) * time.Minute).Unix()
+		claim["granted_permissions"] = user.DirectPermissionNames()
+	}
 
-		claim["name"] = user.Username
-		claim["email"] = user.Email
-		claim["is_admin"] = user.Role.IsAdmin
-		claim["validated"] = user.ValidatedAt.Valid
-		claim["validated_at"] = user.ValidatedAt.Time.Unix()
+	// Denied permission is carried in both mode, it must win over permission implied by wildcard
+	claim["denied_permissions"] = user.DeniedPermissionNames()
+}
 
-		var permissions []string
-		for _, permission := range user.Role.Permissions {
-			permissions = append(permissions, permission.Name)
-		}
+// roleVersionClaim return token version of every role assigned or granted to user keyed by role id
+func roleVersionClaim(user *entity.User) map[string]uint {
+	roles := map[string]uint{}
+	for _, role := range user.EffectiveRoles() {
+		roles[strconv.FormatUint(uint64(role.ID), 10)] = role.TokenVersion
+	}
+
+	return roles
+}
 
-		claim["permissions"] = permissions
+// GenerateClientToken sign access token for OAuth2 client, subject is the client id and the
+// token carry no user identity, only the granted permissions and their scope form
+func GenerateClientToken(client *entity.Client, permissions []string, jti string, expireTime int, keyRing *KeyRing) (string, error) {
+	scopes := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		scopes = append(scopes, utils.PermissionToScope(permission))
 	}
 
-	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claim).SignedString(key)
+	claim := make(jwt.MapClaims)
+	claim["sub"] = client.ClientID
+	claim["client_id"] = client.ClientID
+	claim["client_ver"] = client.TokenVersion
+	claim["scope"] = strings.Join(scopes, " ")
+	claim["permissions"] = permissions
+	claim["jti"] = jti
+	claim["iat"] = time.Now().Unix()
+	claim["nbf"] = time.Now().Unix()
+	claim["exp"] = time.Now().Add(time.Duration(expireTime) * time.Minute).Unix()
+
+	token, err := keyRing.Sign(claim)
 	if err != nil {
 		return "", fmt.Errorf("could not generate token: %w", err)
 	}
@@ -52,27 +127,37 @@ func GenerateToken(user *entity.User, expireTime int, secret string, isRefresh b
 	return token, nil
 }
 
-func ValidateToken(token string, secret string) (jwt.MapClaims, error) {
-	decodedSecret, err := base64.StdEncoding.DecodeString(secret)
-	if err != nil {
-		return nil, fmt.Errorf("could not decode token secret: %w", err)
+// GenerateChallengeToken create short lived token that only carry subject and purpose,
+// used for intermediate step (e.g. two-factor verification) before real token issued
+func GenerateChallengeToken(userID uint, purpose string, expireTime int, keyRing *KeyRing) (string, error) {
+	return GenerateChallengeTokenWithClaims(userID, purpose, nil, expireTime, keyRing)
+}
+
+// GenerateChallengeTokenWithClaims create challenge token carrying extra claims the next step
+// must check, extra claims could not override subject, purpose or lifetime
+func GenerateChallengeTokenWithClaims(
+	userID uint, purpose string, extra map[string]interface{}, expireTime int, keyRing *KeyRing,
+) (string, error) {
+	claim := make(jwt.MapClaims)
+	for key, value := range extra {
+		claim[key] = value
 	}
+	claim["sub"] = userID
+	claim["purpose"] = purpose
+	claim["iat"] = time.Now().Unix()
+	claim["nbf"] = time.Now().Unix()
+	claim["exp"] = time.Now().Add(time.Duration(expireTime) * time.Minute).Unix()
 
-	key, err := jwt.ParseRSAPublicKeyFromPEM(decodedSecret)
+	token, err := keyRing.Sign(claim)
 	if err != nil {
-		return nil, fmt.Errorf("validate: parse key: %w", err)
+		return "", fmt.Errorf("could not generate token: %w", err)
 	}
 
-	parsedToken, err := jwt.Parse(
-		token,
-		func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
-				return nil, fmt.Errorf("unexpected method: %s", t.Header["alg"])
-			}
+	return token, nil
+}
 
-			return key, nil
-		},
-	)
+func ValidateToken(token string, keyRing *KeyRing) (jwt.MapClaims, error) {
+	parsedToken, err := jwt.Parse(token, keyRing.KeyFunc)
 
 	if err != nil {
 		return nil, fmt.Errorf("validate: %w", err)
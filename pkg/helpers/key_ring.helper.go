@@ -0,0 +1,241 @@
+package helpers
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+)
+
+// DefaultKeyID is the kid given to key from legacy single PEM config
+// (JWT_*_PRIVATE_SECRET / JWT_*_PUBLIC_SECRET)
+const DefaultKeyID = "default"
+
+// KeyRing hold every RSA key that token could be verified with, and the one key
+// used to sign new token. Retired key stay in the ring until token signed with it expired
+type KeyRing struct {
+	signingKID string
+	signingKey *rsa.PrivateKey
+	publicKeys map[string]*rsa.PublicKey
+	kids       []string
+}
+
+type (
+	JWK struct {
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+
+	JWKS struct {
+		Keys []JWK `json:"keys"`
+	}
+)
+
+var (
+	keyRingMutex sync.Mutex
+	keyRingCache = map[string]*KeyRing{}
+)
+
+// AccessKeyRing return key ring for access token based on current config
+func AccessKeyRing() *KeyRing {
+	cfg := config.AppConfig
+	return cachedKeyRing(cfg.JwtAccessKeys, cfg.JwtAccessSigningKID, cfg.JwtAccessPrivateSecret, cfg.JwtAccessPublicSecret)
+}
+
+// RefreshKeyRing return key ring for refresh token based on current config
+func RefreshKeyRing() *KeyRing {
+	cfg := config.AppConfig
+	return cachedKeyRing(cfg.JwtRefreshKeys, cfg.JwtRefreshSigningKID, cfg.JwtRefreshPrivateSecret, cfg.JwtRefreshPublicSecret)
+}
+
+// ChallengeKeyRing return key ring for challenge token based on current config, challenge token
+// must never be signed by access key since that one is published for other service to verify
+// access token with
+func ChallengeKeyRing() *KeyRing {
+	cfg := config.AppConfig
+	if cfg.JwtChallengeKeys == "" {
+		return RefreshKeyRing()
+	}
+
+	return cachedKeyRing(cfg.JwtChallengeKeys, cfg.JwtChallengeSigningKID, "", "")
+}
+
+// cachedKeyRing parse the ring once per distinct config value, so PEM is not parsed on every
+// request while config reload still take effect. Invalid config give empty ring that reject everything
+func cachedKeyRing(keys, signingKID, privateSecret, publicSecret string) *KeyRing {
+	cacheKey := strings.Join([]string{keys, signingKID, privateSecret, publicSecret}, "|")
+
+	keyRingMutex.Lock()
+	defer keyRingMutex.Unlock()
+
+	if ring, ok := keyRingCache[cacheKey]; ok {
+		return ring
+	}
+
+	ring, err := NewKeyRing(keys, signingKID, privateSecret, publicSecret)
+	if err != nil {
+		log.Printf("Error loading JWT key ring: %v", err)
+		ring = &KeyRing{publicKeys: map[string]*rsa.PublicKey{}}
+	}
+
+	keyRingCache[cacheKey] = ring
+	return ring
+}
+
+// NewKeyRing build ring from comma separated "kid:base64_pem" entries, where PEM may be either
+// private key (able to sign) or public key (verify only). Legacy private and public secret,
+// when set, are registered under DefaultKeyID so token issued before rotation keep working
+func NewKeyRing(keys, signingKID, privateSecret, publicSecret string) (*KeyRing, error) {
+	ring := &KeyRing{publicKeys: map[string]*rsa.PublicKey{}}
+	privateKeys := map[string]*rsa.PrivateKey{}
+
+	if privateSecret != "" || publicSecret != "" {
+		if privateSecret != "" {
+			key, err := parseRSAKey(privateSecret, true)
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %w", DefaultKeyID, err)
+			}
+			privateKeys[DefaultKeyID] = key.(*rsa.PrivateKey)
+		}
+
+		if publicSecret != "" {
+			key, err := parseRSAKey(publicSecret, false)
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %w", DefaultKeyID, err)
+			}
+			ring.addPublicKey(DefaultKeyID, key.(*rsa.PublicKey))
+		} else {
+			ring.addPublicKey(DefaultKeyID, &privateKeys[DefaultKeyID].PublicKey)
+		}
+	}
+
+	for _, entry := range strings.Split(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, encoded, found := strings.Cut(entry, ":")
+		if !found || kid == "" || encoded == "" {
+			return nil, fmt.Errorf("invalid key entry, expected kid:base64_pem")
+		}
+
+		if _, exists := ring.publicKeys[kid]; exists {
+			return nil, fmt.Errorf("duplicate key id %s", kid)
+		}
+
+		// Entry holding private key also publish its public half
+		if key, err := parseRSAKey(encoded, true); err == nil {
+			privateKey := key.(*rsa.PrivateKey)
+			privateKeys[kid] = privateKey
+			ring.addPublicKey(kid, &privateKey.PublicKey)
+			continue
+		}
+
+		key, err := parseRSAKey(encoded, false)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+		ring.addPublicKey(kid, key.(*rsa.PublicKey))
+	}
+
+	if signingKID == "" {
+		signingKID = DefaultKeyID
+	}
+
+	if key, ok := privateKeys[signingKID]; ok {
+		ring.signingKID = signingKID
+		ring.signingKey = key
+	} else if len(privateKeys) > 0 {
+		return nil, fmt.Errorf("signing key %s not found or has no private key", signingKID)
+	}
+
+	return ring, nil
+}
+
+func (k *KeyRing) addPublicKey(kid string, key *rsa.PublicKey) {
+	k.publicKeys[kid] = key
+	k.kids = append(k.kids, kid)
+}
+
+// Sign sign claim with the current signing key and put its kid on header
+func (k *KeyRing) Sign(claim jwt.Claims) (string, error) {
+	if k.signingKey == nil {
+		return "", fmt.Errorf("no signing key configured")
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claim)
+	token.Header["kid"] = k.signingKID
+
+	return token.SignedString(k.signingKey)
+}
+
+// KeyFunc select verification key by kid header, token without kid (issued before
+// key ring) is checked against the default key
+func (k *KeyRing) KeyFunc(t *jwt.Token) (interface{}, error) {
+	if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+		return nil, fmt.Errorf("unexpected method: %s", t.Header["alg"])
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		kid = DefaultKeyID
+	}
+
+	key, ok := k.publicKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+
+	return key, nil
+}
+
+// JWKS return every verification key in JSON Web Key Set format (RFC 7517)
+func (k *KeyRing) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+
+	for _, kid := range k.kids {
+		key := k.publicKeys[kid]
+		jwks.Keys = append(jwks.Keys, JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			Kid: kid,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+
+	return jwks
+}
+
+func parseRSAKey(encoded string, isPrivate bool) (interface{}, error) {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode token secret: %w", err)
+	}
+
+	if isPrivate {
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(decoded)
+		if err != nil {
+			return nil, fmt.Errorf("parse token secret key: %w", err)
+		}
+		return key, nil
+	}
+
+	key, err := jwt.ParseRSAPublicKeyFromPEM(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("parse key: %w", err)
+	}
+	return key, nil
+}
@@ -46,20 +46,23 @@ type LogAPIParam struct {
 	UserAgent     string                 `json:"user_agent"`
 	ClientIP      string                 `json:"client_ip"`
 	Username      string                 `json:"username"`
+	Impersonator  string                 `json:"impersonator"`
 	StartTime     time.Time              `json:"start_time"`
 	EndTime       time.Time              `json:"end_time"`
 }
 
 type LogSystemParam struct {
-	Identifier string
-	StatusCode int
-	Location   string
-	Message    string
-	StartTime  time.Time
-	EndTime    time.Time
-	Duration   string
-	Username   string
-	Err        interface{}
+	Identifier   string
+	Category     string
+	StatusCode   int
+	Location     string
+	Message      string
+	StartTime    time.Time
+	EndTime      time.Time
+	Duration     string
+	Username     string
+	Impersonator string
+	Err          interface{}
 }
 
 type Log struct {
@@ -200,7 +203,10 @@ func APILogger(logger *zap.Logger) fiber.Handler {
 					"raw": string(responseBody),
 				}
 			} else {
-				RedactFields(jsonResponseBody, []string{"key", "token", "password", "re_password", "old_password", "raw"})
+				RedactFields(jsonResponseBody, []string{
+					"key", "token", "password", "re_password", "old_password", "raw",
+					"secret", "otpauth_uri", "recovery_codes", "challenge_token", "access_token", "refresh_token",
+				})
 			}
 		}
 
@@ -209,6 +215,9 @@ func APILogger(logger *zap.Logger) fiber.Handler {
 		if _, exists := headers["Authorization"]; exists {
 			headers["Authorization"] = []string{"[REDACTED]"}
 		}
+		if _, exists := headers["X-Api-Key"]; exists {
+			headers["X-Api-Key"] = []string{"[REDACTED]"}
+		}
 
 		// Get other data
 		identifier := c.GetRespHeader(fiber.HeaderXRequestID)
@@ -228,6 +237,9 @@ func APILogger(logger *zap.Logger) fiber.Handler {
 			username = ""
 		}
 
+		// Admin acting as the user through impersonation token
+		impersonator, _ := c.Locals("impersonator").(string)
+
 		// Log message
 		message := "API LOG"
 		if msg, ok := jsonResponseBody["message"]; ok {
@@ -250,6 +262,7 @@ func APILogger(logger *zap.Logger) fiber.Handler {
 			UserAgent:     userAgent,
 			ClientIP:      clientIP,
 			Username:      username,
+			Impersonator:  impersonator,
 			StartTime:     startTime,
 			EndTime:       endTime,
 		}
@@ -291,6 +304,7 @@ func GenerateLogAPI(apiLogData LogAPIParam) {
 		zap.String("user_agent", apiLogData.UserAgent),
 		zap.String("client_ip", apiLogData.ClientIP),
 		zap.String("username", apiLogData.Username),
+		zap.String("impersonator", apiLogData.Impersonator),
 		zap.Time("start_time", apiLogData.StartTime),
 		zap.Time("end_time", apiLogData.EndTime),
 		zap.String("duration", duration),
@@ -306,6 +320,8 @@ func GenerateLogSystem(logData LogSystemParam) {
 	)
 
 	switch {
+	case logData.Category != "":
+		category = logData.Category
 	case logData.StatusCode >= 500:
 		category = "FATAL"
 	case logData.StatusCode >= 400:
@@ -325,6 +341,7 @@ func GenerateLogSystem(logData LogSystemParam) {
 		zap.String("duration", duration),
 		zap.String("identifier", logData.Identifier),
 		zap.Any("username", logData.Username),
+		zap.String("impersonator", logData.Impersonator),
 		zap.Any("errors", logData.Err),
 		zap.String("human_time", humanTime),
 	)
@@ -445,6 +462,9 @@ func CreateLogSystem23(ctx context.Context, logData *Log) {
 	if username, ok := ctx.Value("username").(string); ok {
 		logSysData.Username = username
 	}
+	if impersonator, ok := ctx.Value(constant.CtxKeyImpersonator).(string); ok {
+		logSysData.Impersonator = impersonator
+	}
 
 	// duration := FormatDuration(logSysData.StartTime, logSysData.EndTime)
 
@@ -474,11 +494,15 @@ func ExtractIdentifierAndUsername(c *fiber.Ctx) context.Context {
 	if sessionUserAdmin := c.Locals("is_admin"); sessionUserAdmin != nil {
 		is_admin = sessionUserAdmin.(bool)
 	}
+	impersonator, _ := c.Locals("impersonator").(string)
 
 	ctx = context.WithValue(ctx, constant.CtxKeyIdentifier, identifier)
 	ctx = context.WithValue(ctx, constant.CtxKeyUsername, username)
 	ctx = context.WithValue(ctx, constant.CtxKeyUserID, user_id)
 	ctx = context.WithValue(ctx, constant.CtxKeyIsAdmin, is_admin)
+	ctx = context.WithValue(ctx, constant.CtxKeyImpersonator, impersonator)
+	ctx = context.WithValue(ctx, constant.CtxKeyIPAddress, c.IP())
+	ctx = context.WithValue(ctx, constant.CtxKeyUserAgent, c.Get(fiber.HeaderUserAgent))
 
 	return ctx
 }
@@ -526,6 +550,32 @@ func LogSystemWithDefer(ctx context.Context, logData *Log) func() {
 	}
 }
 
+// LogSecurityEvent push security related event (token reuse, account lockout, etc.) into system log
+// with SECURITY category so it could be filtered and reviewed separately
+func LogSecurityEvent(ctx context.Context, event string, detail interface{}) {
+	logSysData := LogSystemParam{
+		Category:   "SECURITY",
+		StatusCode: fiber.StatusForbidden,
+		Location:   "security",
+		Message:    event,
+		StartTime:  time.Now(),
+		EndTime:    time.Now(),
+		Err:        detail,
+	}
+
+	if identifier, ok := ctx.Value(constant.CtxKeyIdentifier).(string); ok {
+		logSysData.Identifier = identifier
+	}
+	if username, ok := ctx.Value(constant.CtxKeyUsername).(string); ok {
+		logSysData.Username = username
+	}
+	if impersonator, ok := ctx.Value(constant.CtxKeyImpersonator).(string); ok {
+		logSysData.Impersonator = impersonator
+	}
+
+	LogSysChannel <- logSysData
+}
+
 func LogBaseResponse(logData *Log, response BaseResponse) BaseResponse {
 	logData.Message = response.Message
 	logData.Err = response.Errors
@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sayyidinside/gofiber-clean-fresh/infrastructure/config"
+)
+
+// ValidatePassword check password against configured policy rule, every broken rule
+// is reported under "password" field with tag naming the rule. Username and email
+// of the owner are given so password could not be derived from identity
+func ValidatePassword(password, username, email string) []ValidationError {
+	cfg := config.AppConfig
+	errs := []ValidationError{}
+
+	if cfg.PasswordMinLength > 0 && utf8.RuneCountInString(password) < cfg.PasswordMinLength {
+		errs = append(errs, passwordError("min_length", strconv.Itoa(cfg.PasswordMinLength)))
+	}
+
+	// Measured in bytes, bcrypt silently ignore anything after the limit
+	if cfg.PasswordMaxLength > 0 && len(password) > cfg.PasswordMaxLength {
+		errs = append(errs, passwordError("max_length", strconv.Itoa(cfg.PasswordMaxLength)))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char) || unicode.IsSpace(char):
+			hasSymbol = true
+		}
+	}
+
+	if cfg.PasswordRequireUpper && !hasUpper {
+		errs = append(errs, passwordError("uppercase", ""))
+	}
+	if cfg.PasswordRequireLower && !hasLower {
+		errs = append(errs, passwordError("lowercase", ""))
+	}
+	if cfg.PasswordRequireDigit && !hasDigit {
+		errs = append(errs, passwordError("digit", ""))
+	}
+	if cfg.PasswordRequireSymbol && !hasSymbol {
+		errs = append(errs, passwordError("symbol", ""))
+	}
+
+	if cfg.PasswordDisallowIdentity {
+		lowered := strings.ToLower(password)
+
+		// Very short identity would match too many password by chance
+		if username = strings.ToLower(username); len(username) >= 3 && strings.Contains(lowered, username) {
+			errs = append(errs, passwordError("contains_username", ""))
+		}
+
+		localPart, _, _ := strings.Cut(strings.ToLower(email), "@")
+		if len(localPart) >= 3 && strings.Contains(lowered, localPart) {
+			errs = append(errs, passwordError("contains_email", ""))
+		}
+	}
+
+	return errs
+}
+
+func passwordError(tag, param string) ValidationError {
+	return ValidationError{
+		Field: "password",
+		Tag:   tag,
+		Param: param,
+	}
+}
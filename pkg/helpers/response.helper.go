@@ -79,6 +79,7 @@ func ResponseFormatter(c *fiber.Ctx, res BaseResponse) error {
 		Err:        res.Errors,
 		Username:   username,
 	}
+	logSysData.Impersonator, _ = c.Locals("impersonator").(string)
 
 	LogSysChannel <- logSysData
 
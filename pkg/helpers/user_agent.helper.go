@@ -0,0 +1,67 @@
+package helpers
+
+import "strings"
+
+type userAgentRule struct {
+	token string
+	label string
+}
+
+// Order matter, more specific token must come first since most browser
+// also advertise the engine of other browser (Edge contain "Chrome" and "Safari")
+var (
+	browserRules = []userAgentRule{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"SamsungBrowser/", "Samsung Internet"},
+		{"Firefox/", "Firefox"},
+		{"CriOS/", "Chrome"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"PostmanRuntime/", "Postman"},
+		{"curl/", "curl"},
+		{"okhttp/", "OkHttp"},
+	}
+
+	platformRules = []userAgentRule{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// ParseDeviceLabel build short human readable label such as "Chrome on Windows"
+// from user agent header, unknown part is reported as "Unknown"
+func ParseDeviceLabel(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown"
+	}
+
+	browser := matchUserAgent(userAgent, browserRules)
+	platform := matchUserAgent(userAgent, platformRules)
+
+	switch {
+	case browser == "" && platform == "":
+		return "Unknown"
+	case platform == "":
+		return browser
+	case browser == "":
+		return "Unknown on " + platform
+	}
+
+	return browser + " on " + platform
+}
+
+func matchUserAgent(userAgent string, rules []userAgentRule) string {
+	for _, rule := range rules {
+		if strings.Contains(userAgent, rule.token) {
+			return rule.label
+		}
+	}
+
+	return ""
+}
@@ -9,6 +9,7 @@ import (
 type ValidationError struct {
 	Field string `json:"field"`
 	Tag   string `json:"tag"`
+	Param string `json:"param,omitempty"`
 }
 
 func validateStruct(param any) []ValidationError {
@@ -30,6 +31,7 @@ func validateStruct(param any) []ValidationError {
 			element := ValidationError{
 				Field: formName,
 				Tag:   err.Tag(),
+				Param: err.Param(),
 			}
 			errs = append(errs, element)
 		}
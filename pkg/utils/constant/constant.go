@@ -10,11 +10,54 @@ const (
 	TABLE_PROFILE         string = "profiles"
 	TABLE_REFRESH_TOKEN   string = "refresh_tokens"
 	TABLE_ROLE_PERMISSION string = "role_permissions"
+	TABLE_USER_ROLE       string = "user_roles"
+	TABLE_ROLE_PARENT     string = "role_parents"
+
+	TABLE_USER_TWO_FACTOR          string = "user_two_factors"
+	TABLE_TWO_FACTOR_RECOVERY_CODE string = "two_factor_recovery_codes"
+	TABLE_PASSWORD_RESET           string = "password_resets"
+	TABLE_API_KEY                  string = "api_keys"
+	TABLE_API_KEY_PERMISSION       string = "api_key_permissions"
+	TABLE_CLIENT                   string = "clients"
+	TABLE_CLIENT_PERMISSION        string = "client_permissions"
+	TABLE_PASSWORD_HISTORY         string = "password_histories"
+	TABLE_PERMISSION_IMPLICATION   string = "permission_implications"
+	TABLE_USER_GRANT               string = "user_grants"
+	TABLE_USER_PERMISSION          string = "user_permissions"
+	TABLE_USER_IDENTITY            string = "user_identities"
+
+	// TOKEN PURPOSE
+	TokenPurposeTwoFactorVerify string = "2fa_verify"
+	TokenPurposeTwoFactorEnroll string = "2fa_enroll"
+	TokenPurposeEmailVerify     string = "email_verify"
+	TokenPurposePasswordChange  string = "password_change"
+
+	// PERMISSION MODE
+	PermissionModeClaim   string = "claim"
+	PermissionModeRuntime string = "runtime"
+
+	// USER PERMISSION OVERRIDE EFFECT, deny win over anything granted by role
+	PermissionEffectAllow string = "allow"
+	PermissionEffectDeny  string = "deny"
+
+	// PERMISSION WILDCARD, permission named "<Module>:*" grant every permission of the module
+	PermissionWildcardSuffix string = ":*"
+
+	// CACHE KEY
+	CacheKeyAccessDenyList         string = "deny-list:access-token:jti:"
+	CacheKeyOidcState              string = "oidc:state:"
+	CacheKeyPermissionImplications string = "cache:permission-implications"
+
+	// LOCK KEY
+	LockKeyGrantJob string = "lock:grant-job"
 
 	// CONTEXT KEY
-	CtxKeyIdentifier contextKey = "identifier"
-	CtxKeyUsername   contextKey = "username"
-	CtxKeyUserID     contextKey = "user_id"
-	CtxKeyIsAdmin    contextKey = "is_admin"
-	CtxKeyFunction   contextKey = "function"
+	CtxKeyIdentifier   contextKey = "identifier"
+	CtxKeyUsername     contextKey = "username"
+	CtxKeyUserID       contextKey = "user_id"
+	CtxKeyIsAdmin      contextKey = "is_admin"
+	CtxKeyImpersonator contextKey = "impersonator"
+	CtxKeyFunction     contextKey = "function"
+	CtxKeyIPAddress    contextKey = "ip_address"
+	CtxKeyUserAgent    contextKey = "user_agent"
 )
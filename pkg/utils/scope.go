@@ -0,0 +1,9 @@
+package utils
+
+import "strings"
+
+// PermissionToScope turn permission name into OAuth2 scope token, scope is space separated
+// so "View User" become "view_user"
+func PermissionToScope(permission string) string {
+	return strings.ToLower(strings.Join(strings.Fields(permission), "_"))
+}